@@ -0,0 +1,74 @@
+package v1alpha1
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+)
+
+// Client is a thin typed REST client for the ValkeyCluster resource, scoped to one namespace.
+//
+// TODO: once code-generator is wired into the build (tracked alongside this change), this
+// hand-written client, and the manual DeepCopy methods in zz_generated.deepcopy.go, should be
+// replaced by the generated clientset/listers/informers so this package only needs to carry types.
+type Client struct {
+	restClient rest.Interface
+	namespace  string
+}
+
+// NewForConfig builds a Client talking to the apiserver described by cfg, scoped to namespace.
+func NewForConfig(cfg *rest.Config, namespace string) (*Client, error) {
+	configCopy := *cfg
+	configCopy.GroupVersion = &SchemeGroupVersion
+	configCopy.APIPath = "/apis"
+	configCopy.NegotiatedSerializer = serializer.NewCodecFactory(scheme.Scheme)
+
+	restClient, err := rest.RESTClientFor(&configCopy)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{restClient: restClient, namespace: namespace}, nil
+}
+
+func (c *Client) Get(ctx context.Context, name string) (*ValkeyCluster, error) {
+	result := new(ValkeyCluster)
+
+	err := c.restClient.Get().
+		Namespace(c.namespace).
+		Resource("valkeyclusters").
+		Name(name).
+		Do(ctx).
+		Into(result)
+
+	return result, err
+}
+
+func (c *Client) List(ctx context.Context) (*ValkeyClusterList, error) {
+	result := new(ValkeyClusterList)
+
+	err := c.restClient.Get().
+		Namespace(c.namespace).
+		Resource("valkeyclusters").
+		Do(ctx).
+		Into(result)
+
+	return result, err
+}
+
+func (c *Client) UpdateStatus(ctx context.Context, vc *ValkeyCluster) (*ValkeyCluster, error) {
+	result := new(ValkeyCluster)
+
+	err := c.restClient.Put().
+		Namespace(c.namespace).
+		Resource("valkeyclusters").
+		Name(vc.Name).
+		SubResource("status").
+		Body(vc).
+		Do(ctx).
+		Into(result)
+
+	return result, err
+}
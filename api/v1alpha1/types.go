@@ -0,0 +1,120 @@
+// Package v1alpha1 contains the ValkeyCluster API, group cache.tkhq.io.
+//
+// +kubebuilder:object:generate=true
+// +groupName=cache.tkhq.io
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Shards",type=integer,JSONPath=`.spec.shards`
+// +kubebuilder:printcolumn:name="Replicas/Shard",type=integer,JSONPath=`.spec.replicasPerShard`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+
+// ValkeyCluster declares the desired shape of a sharded Valkey deployment: shard count,
+// replicas-per-shard, resource sizing, TLS material, and backup policy, in one place. The
+// underlying StatefulSet and the runtime cluster topology (slots, replication) are both
+// reconciled by the controller package from this one resource.
+type ValkeyCluster struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ValkeyClusterSpec   `json:"spec"`
+	Status ValkeyClusterStatus `json:"status,omitempty"`
+}
+
+// ValkeyClusterSpec is the desired state of a ValkeyCluster.
+type ValkeyClusterSpec struct {
+	// Shards is the number of primary shards in the cluster.
+	// +kubebuilder:validation:Minimum=1
+	Shards int32 `json:"shards"`
+
+	// ReplicasPerShard is the number of replicas kept for each primary shard.
+	// +kubebuilder:validation:Minimum=0
+	ReplicasPerShard int32 `json:"replicasPerShard"`
+
+	// Resources are applied to every pod in the underlying StatefulSet.
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// TLS references the material used to secure both client and peer (bus) traffic. If nil, TLS
+	// is disabled.
+	TLS *TLSSpec `json:"tls,omitempty"`
+
+	// Backup is the snapshot schedule and destination applied to every shard's primary.
+	Backup *BackupPolicy `json:"backup,omitempty"`
+}
+
+// TLSSpec references the Secret providing TLS material for valkey and the sidecar.
+type TLSSpec struct {
+	// SecretRef names the Secret (in the same namespace) containing tls.crt, tls.key and ca.crt.
+	SecretRef corev1.LocalObjectReference `json:"secretRef"`
+}
+
+// BackupPolicy configures periodic RDB snapshotting to S3-compatible object storage.
+type BackupPolicy struct {
+	// S3Endpoint is the S3-compatible endpoint snapshots are uploaded to and restored from.
+	S3Endpoint string `json:"s3Endpoint"`
+
+	// S3Bucket is the bucket snapshots are stored under.
+	S3Bucket string `json:"s3Bucket"`
+
+	// Schedule is a standard cron expression controlling how often a snapshot is taken.
+	Schedule string `json:"schedule"`
+
+	// Retention is the number of snapshots kept per shard.
+	// +kubebuilder:validation:Minimum=1
+	Retention int32 `json:"retention,omitempty"`
+}
+
+// ValkeyClusterStatus is the observed state of a ValkeyCluster, as last reconciled by the
+// controller and reported by the per-pod sidecars.
+type ValkeyClusterStatus struct {
+	// Shards reports the observed role and slot ownership of each shard, indexed by shard number.
+	Shards []ShardStatus `json:"shards,omitempty"`
+
+	// SlotAssignments maps slot ranges to the primary pod that owns them, as "<start>-<end>":
+	// "<podName>". It is the source of truth sidecars use to determine their own role and index,
+	// in place of the index-modulo-primaryCount heuristic used when no ValkeyCluster is present.
+	SlotAssignments map[string]string `json:"slotAssignments,omitempty"`
+
+	// Conditions surfaces observed cluster health (state, epoch, known nodes, slots assigned) so
+	// `kubectl get valkeycluster` shows readiness at a glance.
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// ShardStatus is the observed state of a single shard.
+type ShardStatus struct {
+	// Index is the shard number, matching the primary's ordinal within the StatefulSet at the
+	// time it was first assigned.
+	Index int32 `json:"index"`
+
+	// PrimaryPodName is the pod currently serving as this shard's primary.
+	PrimaryPodName string `json:"primaryPodName"`
+
+	// ReplicaPodNames are the pods currently replicating PrimaryPodName.
+	ReplicaPodNames []string `json:"replicaPodNames,omitempty"`
+
+	// SlotStart and SlotEnd are the inclusive bounds of the slot range owned by this shard.
+	SlotStart int32 `json:"slotStart"`
+	SlotEnd   int32 `json:"slotEnd"`
+}
+
+// Well-known ValkeyClusterStatus condition types.
+const (
+	// ConditionReady is true once every shard reports cluster_state:ok for its primary.
+	ConditionReady = "Ready"
+)
+
+// +kubebuilder:object:root=true
+
+// ValkeyClusterList is a list of ValkeyCluster.
+type ValkeyClusterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []ValkeyCluster `json:"items"`
+}
@@ -0,0 +1,295 @@
+// Package backup periodically snapshots the local valkey instance's RDB file to S3-compatible
+// object storage, and can restore one back down before the instance joins the cluster.
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/robfig/cron/v3"
+	"github.com/valkey-io/valkey-go"
+
+	"github.com/tkhq/valkey-manager/cluster"
+)
+
+const (
+	// RDBFileName is the name of the RDB dump file valkey writes into its data directory.
+	RDBFileName = "dump.rdb"
+
+	// ArchiveName is the name of the tarball uploaded to object storage for a snapshot.
+	ArchiveName = "dump.tar.gz"
+
+	// ObjectKeyTimeLayout formats the timestamp component of a snapshot's object key.
+	ObjectKeyTimeLayout = "20060102T150405Z"
+
+	// BGSavePollInterval is how often we poll INFO persistence while waiting for BGSAVE to finish.
+	BGSavePollInterval = time.Second
+)
+
+// Config configures the backup/restore subsystem.
+type Config struct {
+	// S3Endpoint is the S3-compatible endpoint snapshots are uploaded to and restored from.
+	S3Endpoint string
+
+	// S3Bucket is the bucket snapshots are stored under.
+	S3Bucket string
+
+	// Schedule is a standard cron expression controlling how often a snapshot is taken.
+	Schedule string
+
+	// Retention is the number of snapshots kept per shard; older snapshots beyond this are
+	// pruned, but at least one snapshot per shard is always kept.
+	Retention int
+
+	// ClusterName namespaces snapshots from other clusters sharing the same bucket.
+	ClusterName string
+
+	// DataDir is the directory valkey writes its RDB dump into.
+	DataDir string
+}
+
+// Backupper periodically snapshots the local valkey instance, when it is a primary, to
+// S3-compatible object storage.
+type Backupper struct {
+	cfg   Config
+	store *minio.Client
+}
+
+func NewBackupper(cfg Config) (*Backupper, error) {
+	store, err := minio.New(cfg.S3Endpoint, &minio.Options{
+		Creds:  credentials.NewEnvAWS(),
+		Secure: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create object storage client: %w", err)
+	}
+
+	return &Backupper{cfg: cfg, store: store}, nil
+}
+
+// Run blocks, snapshotting the local shard on cfg.Schedule until ctx is cancelled. Snapshots are
+// skipped (without error) on ticks where the local node is not currently a primary, since replicas
+// don't need to take their own independent backups of the same data.
+func (b *Backupper) Run(ctx context.Context, vc valkey.Client, shardIndex int) error {
+	sched, err := cron.ParseStandard(b.cfg.Schedule)
+	if err != nil {
+		return fmt.Errorf("failed to parse backup schedule %q: %w", b.cfg.Schedule, err)
+	}
+
+	for next := sched.Next(time.Now()); ; next = sched.Next(time.Now()) {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(time.Until(next)):
+		}
+
+		if err := b.tick(ctx, vc, shardIndex); err != nil {
+			slog.Error("failed to snapshot shard", slog.Int("shard_index", shardIndex), slog.String("error", err.Error()))
+		}
+	}
+}
+
+func (b *Backupper) tick(ctx context.Context, vc valkey.Client, shardIndex int) error {
+	isPrimary, err := cluster.IsPrimary(ctx, vc)
+	if err != nil {
+		return fmt.Errorf("failed to determine node role: %w", err)
+	}
+
+	if !isPrimary {
+		slog.Debug("skipping backup; local node is not a primary", slog.Int("shard_index", shardIndex))
+
+		return nil
+	}
+
+	if err := b.Snapshot(ctx, vc, shardIndex); err != nil {
+		return err
+	}
+
+	return b.prune(ctx, shardIndex)
+}
+
+// Snapshot triggers BGSAVE, waits for it to complete, tars and gzips the resulting RDB file, and
+// uploads it to "s3://<bucket>/<cluster>/<shard-index>/<epoch>/<timestamp>-dump.tar.gz".
+func (b *Backupper) Snapshot(ctx context.Context, vc valkey.Client, shardIndex int) error {
+	if err := triggerBGSave(ctx, vc); err != nil {
+		return fmt.Errorf("failed to trigger BGSAVE: %w", err)
+	}
+
+	epoch, err := localEpoch(ctx, vc)
+	if err != nil {
+		return fmt.Errorf("failed to read cluster epoch: %w", err)
+	}
+
+	rdbPath := filepath.Join(b.cfg.DataDir, RDBFileName)
+
+	archivePath, err := tarGzip(rdbPath)
+	if err != nil {
+		return fmt.Errorf("failed to archive %s: %w", rdbPath, err)
+	}
+	defer os.Remove(archivePath)
+
+	key := objectKey(b.cfg.ClusterName, epoch, shardIndex, time.Now())
+
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to open archive %s: %w", archivePath, err)
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat archive %s: %w", archivePath, err)
+	}
+
+	if _, err := b.store.PutObject(ctx, b.cfg.S3Bucket, key, f, stat.Size(), minio.PutObjectOptions{
+		ContentType: "application/gzip",
+	}); err != nil {
+		return fmt.Errorf("failed to upload snapshot to s3://%s/%s: %w", b.cfg.S3Bucket, key, err)
+	}
+
+	slog.Info("uploaded snapshot",
+		slog.String("bucket", b.cfg.S3Bucket),
+		slog.String("key", key),
+		slog.Int64("bytes", stat.Size()),
+	)
+
+	return nil
+}
+
+// prune removes snapshots beyond cfg.Retention for shardIndex, always keeping at least one.
+func (b *Backupper) prune(ctx context.Context, shardIndex int) error {
+	prefix := fmt.Sprintf("%s/", shardPrefix(b.cfg.ClusterName, shardIndex))
+
+	var keys []string
+
+	for obj := range b.store.ListObjects(ctx, b.cfg.S3Bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if obj.Err != nil {
+			return fmt.Errorf("failed to list snapshots under %s: %w", prefix, obj.Err)
+		}
+
+		keys = append(keys, obj.Key)
+	}
+
+	if len(keys) <= b.cfg.Retention || len(keys) <= 1 {
+		return nil
+	}
+
+	// Object keys embed a sortable timestamp, so lexical order is chronological order.
+	sort.Strings(keys)
+
+	toDelete := keys[:len(keys)-max(b.cfg.Retention, 1)]
+
+	for _, key := range toDelete {
+		if err := b.store.RemoveObject(ctx, b.cfg.S3Bucket, key, minio.RemoveObjectOptions{}); err != nil {
+			return fmt.Errorf("failed to prune snapshot %s: %w", key, err)
+		}
+
+		slog.Info("pruned expired snapshot", slog.String("key", key))
+	}
+
+	return nil
+}
+
+func triggerBGSave(ctx context.Context, vc valkey.Client) error {
+	if err := vc.Do(ctx, vc.B().Bgsave().Build()).Error(); err != nil {
+		return err
+	}
+
+	for {
+		inProgress, err := vc.Do(ctx, vc.B().Info().Section("persistence").Build()).ToString()
+		if err != nil {
+			return fmt.Errorf("failed to read persistence info: %w", err)
+		}
+
+		if !bgsaveInProgress(inProgress) {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(BGSavePollInterval):
+		}
+	}
+}
+
+func bgsaveInProgress(info string) bool {
+	ci, err := cluster.InfoFromString(info)
+	if err != nil {
+		return false
+	}
+
+	return ci["rdb_bgsave_in_progress"] == "1"
+}
+
+func localEpoch(ctx context.Context, vc valkey.Client) (int32, error) {
+	infoReader, err := vc.Do(ctx, vc.B().ClusterInfo().Build()).ToString()
+	if err != nil {
+		return 0, err
+	}
+
+	ci, err := cluster.InfoFromString(infoReader)
+	if err != nil {
+		return 0, err
+	}
+
+	return ci.LocalEpoch(), nil
+}
+
+func objectKey(clusterName string, epoch int32, shardIndex int, ts time.Time) string {
+	return fmt.Sprintf("%s/%d/%s-%s", shardPrefix(clusterName, shardIndex), epoch, ts.UTC().Format(ObjectKeyTimeLayout), ArchiveName)
+}
+
+func shardPrefix(clusterName string, shardIndex int) string {
+	return fmt.Sprintf("%s/%s", clusterName, strconv.Itoa(shardIndex))
+}
+
+func tarGzip(path string) (string, error) {
+	in, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+
+	stat, err := in.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	out, err := os.CreateTemp("", "valkey-snapshot-*.tar.gz")
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+
+	gz := gzip.NewWriter(out)
+	defer gz.Close()
+
+	tw := tar.NewWriter(gz)
+	defer tw.Close()
+
+	if err := tw.WriteHeader(&tar.Header{
+		Name: RDBFileName,
+		Mode: 0o600,
+		Size: stat.Size(),
+	}); err != nil {
+		return "", err
+	}
+
+	if _, err := io.Copy(tw, in); err != nil {
+		return "", err
+	}
+
+	return out.Name(), nil
+}
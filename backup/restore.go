@@ -0,0 +1,115 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/valkey-io/valkey-go"
+)
+
+// Restore downloads the snapshot at uri (an "s3://<bucket>/<key>" URI), extracts its RDB file into
+// cfg.DataDir, then forces vc to discard its in-memory dataset and reload that file from disk.
+// The reload is required because this sidecar runs in a separate container from valkey with no
+// guaranteed start order: if valkey has already loaded (or created) its own RDB by the time this
+// finishes writing, the restored snapshot would otherwise never actually be loaded. vc is expected
+// to already be reachable (see cluster.WaitPing); cluster membership (slots, epoch) is then
+// re-established from the restored node ID via the normal reconcile path.
+func (b *Backupper) Restore(ctx context.Context, vc valkey.Client, uri string) error {
+	bucket, key, err := parseS3URI(uri)
+	if err != nil {
+		return err
+	}
+
+	obj, err := b.store.GetObject(ctx, bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to download snapshot s3://%s/%s: %w", bucket, key, err)
+	}
+	defer obj.Close()
+
+	gz, err := gzip.NewReader(obj)
+	if err != nil {
+		return fmt.Errorf("failed to open snapshot archive: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+
+	hdr, err := tr.Next()
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot archive: %w", err)
+	}
+
+	if hdr.Name != RDBFileName {
+		return fmt.Errorf("unexpected file %q in snapshot archive, expected %q", hdr.Name, RDBFileName)
+	}
+
+	if err := os.MkdirAll(b.cfg.DataDir, 0o700); err != nil {
+		return fmt.Errorf("failed to create data dir %s: %w", b.cfg.DataDir, err)
+	}
+
+	dst := filepath.Join(b.cfg.DataDir, RDBFileName)
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", dst, err)
+	}
+
+	if _, err := io.Copy(out, tr); err != nil {
+		out.Close()
+
+		return fmt.Errorf("failed to write %s: %w", dst, err)
+	}
+
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to flush %s: %w", dst, err)
+	}
+
+	if err := reloadFromDisk(ctx, vc); err != nil {
+		return fmt.Errorf("failed to reload restored snapshot into valkey: %w", err)
+	}
+
+	return nil
+}
+
+// reloadFromDisk issues DEBUG RELOAD NOSAVE, which discards valkey's current in-memory dataset and
+// reloads DataDir's RDB file from disk without first saving over it, then checks DBSIZE to confirm
+// the reload actually populated the dataset.
+func reloadFromDisk(ctx context.Context, vc valkey.Client) error {
+	if err := vc.Do(ctx, vc.B().DebugReload().Nosave().Build()).Error(); err != nil {
+		return fmt.Errorf("DEBUG RELOAD NOSAVE failed: %w", err)
+	}
+
+	keyCount, err := vc.Do(ctx, vc.B().Dbsize().Build()).ToInt64()
+	if err != nil {
+		return fmt.Errorf("failed to verify reload via DBSIZE: %w", err)
+	}
+
+	slog.Info("reloaded restored snapshot from disk", slog.Int64("keys", keyCount))
+
+	return nil
+}
+
+func parseS3URI(uri string) (bucket, key string, err error) {
+	const scheme = "s3://"
+
+	if !strings.HasPrefix(uri, scheme) {
+		return "", "", fmt.Errorf("invalid s3 uri %q: must start with %q", uri, scheme)
+	}
+
+	rest := strings.TrimPrefix(uri, scheme)
+
+	bucket, key, found := strings.Cut(rest, "/")
+	if !found || bucket == "" || key == "" {
+		return "", "", fmt.Errorf("invalid s3 uri %q: expected s3://<bucket>/<key>", uri)
+	}
+
+	return bucket, key, nil
+}
@@ -0,0 +1,162 @@
+package cluster
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/valkey-io/valkey-go"
+)
+
+// ClusterBusPortOffset is valkey's fixed offset between a node's client port and its cluster bus
+// port. CLUSTER MEET must be given the bus port when TLS is enabled, since the bus connection is
+// negotiated and authenticated separately from the client connection.
+const ClusterBusPortOffset = 10000
+
+// clientCreds holds the TLS and AUTH settings currently in effect. It is swapped atomically by
+// ClientFactory.Update so certificate rotation takes effect for new connections without
+// disturbing connections already in flight or requiring a pod restart.
+type clientCreds struct {
+	tlsConfig *tls.Config
+	password  string
+	leaf      *x509.Certificate
+}
+
+// ClientFactory builds valkey clients with this deployment's TLS and AUTH settings applied
+// consistently, so the local client, CLUSTER MEET peers, and slot-migration connections all
+// authenticate and encrypt the same way.
+type ClientFactory struct {
+	creds atomic.Pointer[clientCreds]
+}
+
+// NewClientFactory returns a ClientFactory with TLS and AUTH disabled. Call Update once
+// certificate/key material (and, optionally, a password) is available to enable them.
+func NewClientFactory() *ClientFactory {
+	f := &ClientFactory{}
+	f.creds.Store(&clientCreds{})
+
+	return f
+}
+
+// DefaultClientFactory is the ClientFactory used by every package-level helper in this package
+// (WaitPing, Configure, ScaleDown, ...). Callers wire it up via Update once TLS/AUTH material is
+// available; until then it builds plaintext, unauthenticated connections.
+var DefaultClientFactory = NewClientFactory()
+
+// Update installs new TLS certificate/key/CA material and AUTH password, taking effect for every
+// connection built afterwards. certPEM and keyPEM may both be empty to disable TLS client-cert
+// presentation while still setting/rotating caPEM and/or password (e.g. verifying the peer's
+// certificate without presenting one of our own). It is safe to call concurrently with WaitPing.
+func (f *ClientFactory) Update(certPEM, keyPEM, caPEM []byte, password string) error {
+	if len(certPEM) == 0 && len(keyPEM) == 0 && len(caPEM) == 0 {
+		f.creds.Store(&clientCreds{password: password})
+
+		return nil
+	}
+
+	tlsConfig := &tls.Config{}
+
+	var leaf *x509.Certificate
+
+	if len(certPEM) > 0 || len(keyPEM) > 0 {
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return fmt.Errorf("failed to parse TLS certificate/key: %w", err)
+		}
+
+		leaf, err = x509.ParseCertificate(cert.Certificate[0])
+		if err != nil {
+			return fmt.Errorf("failed to parse leaf certificate: %w", err)
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if len(caPEM) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return fmt.Errorf("failed to parse CA certificate")
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	f.creds.Store(&clientCreds{tlsConfig: tlsConfig, password: password, leaf: leaf})
+
+	return nil
+}
+
+func (f *ClientFactory) clientOption(addr string) valkey.ClientOption {
+	creds := f.creds.Load()
+
+	return valkey.ClientOption{
+		InitAddress:       []string{addr},
+		ForceSingleClient: true,
+		Password:          creds.password,
+		TLSConfig:         creds.tlsConfig,
+	}
+}
+
+// TLSEnabled reports whether connections are currently being made over TLS.
+func (f *ClientFactory) TLSEnabled() bool {
+	return f.creds.Load().tlsConfig != nil
+}
+
+// BusPort returns the cluster bus port CLUSTER MEET should advertise for a node listening on
+// port: the fixed TLS bus port when TLS is enabled (since the bus connection needs its own
+// certificate-bearing listener), and the client port itself otherwise.
+func (f *ClientFactory) BusPort(port int) int {
+	if f.TLSEnabled() {
+		return port + ClusterBusPortOffset
+	}
+
+	return port
+}
+
+// CertExpiry returns the current leaf certificate's expiry time, or false if TLS isn't configured.
+func (f *ClientFactory) CertExpiry() (time.Time, bool) {
+	leaf := f.creds.Load().leaf
+	if leaf == nil {
+		return time.Time{}, false
+	}
+
+	return leaf.NotAfter, true
+}
+
+// WaitPing blocks until a valkey client can be built for addr and successfully pings it, retrying
+// with this factory's current TLS/AUTH settings until ctx is cancelled.
+func (f *ClientFactory) WaitPing(ctx context.Context, addr string) (valkey.Client, error) {
+	for {
+		vc, err := valkey.NewClient(f.clientOption(addr))
+		if err == nil {
+			if err = vc.Do(ctx, vc.B().Ping().Build()).Error(); err == nil {
+				slog.Debug("local redis is ready")
+
+				return vc, nil
+			}
+
+			vc.Close()
+		}
+
+		slog.Debug("waiting for local redis to become ready",
+			slog.Duration("wait", PingCheckInterval),
+			slog.String("error", err.Error()),
+		)
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(PingCheckInterval):
+		}
+	}
+}
+
+// WaitPing blocks until a valkey client can be built for addr and successfully pings it, using
+// DefaultClientFactory's current TLS/AUTH settings.
+func WaitPing(ctx context.Context, addr string) (valkey.Client, error) {
+	return DefaultClientFactory.WaitPing(ctx, addr)
+}
@@ -5,11 +5,14 @@ import (
 	"fmt"
 	"log/slog"
 	"net"
+	"os"
 	"strconv"
 	"time"
 
 	"github.com/valkey-io/valkey-go"
 	v1 "k8s.io/api/apps/v1"
+
+	"github.com/tkhq/valkey-manager/api/v1alpha1"
 )
 
 const (
@@ -24,7 +27,13 @@ const (
 	ValkeyPort = 6379
 )
 
-func Configure(ctx context.Context, ss *v1.StatefulSet, ourIndex uint32) error {
+// Configure reconciles the local valkey instance against the desired StatefulSet shape.
+// previousReplicaCount is the replica count last observed for this StatefulSet, or -1 if none has
+// been observed yet (e.g. on manager startup); it is used to detect a scale-down and drain
+// retiring primaries before the new layout is applied. shards is the owning ValkeyCluster's
+// observed shard plan, or nil if this StatefulSet isn't (yet) backed by one, in which case role
+// assignment falls back to the index-modulo-primaryCount heuristic.
+func Configure(ctx context.Context, ss *v1.StatefulSet, ourIndex uint32, previousReplicaCount int32, shards []v1alpha1.ShardStatus) error {
 	if ss == nil || ss.Spec.Replicas == nil {
 		return fmt.Errorf("failed to locate replica count; cannot configure cluster")
 	}
@@ -36,9 +45,26 @@ func Configure(ctx context.Context, ss *v1.StatefulSet, ourIndex uint32) error {
 
 	slog.Info("local redis is alive")
 
-	primaryCount, _ := primariesAndReplicas(int(*ss.Spec.Replicas))
+	newReplicaCount := *ss.Spec.Replicas
+
+	if previousReplicaCount > newReplicaCount {
+		slog.Info("replica count decreased; draining retiring primaries",
+			slog.Int64("previous", int64(previousReplicaCount)),
+			slog.Int64("new", int64(newReplicaCount)),
+		)
 
-	if err := EnsureClusterInitialized(ctx, vc, int(ourIndex), primaryCount); err != nil {
+		if len(shards) > 0 {
+			if err := ScaleDownFromShards(ctx, ss.Name, int(previousReplicaCount), shards); err != nil {
+				return fmt.Errorf("failed to scale down cluster: %w", err)
+			}
+		} else if err := ScaleDown(ctx, int(previousReplicaCount), int(newReplicaCount)); err != nil {
+			return fmt.Errorf("failed to scale down cluster: %w", err)
+		}
+	}
+
+	primaryCount, _ := primariesAndReplicas(int(newReplicaCount))
+
+	if err := EnsureClusterInitialized(ctx, vc, int(ourIndex), primaryCount, shards); err != nil {
 		return fmt.Errorf("failed to ensure cluster is initialized: %w", err)
 	}
 
@@ -55,34 +81,34 @@ func primariesAndReplicas(totalCount int) (primaries, replicas int) {
 	return primaries, totalCount - primaries
 }
 
-func WaitPing(ctx context.Context, addr string) (valkey.Client, error) {
-	for {
-		vc, err := valkey.NewClient(valkey.ClientOption{
-			InitAddress:       []string{addr},
-			ForceSingleClient: true,
-		})
-		if err == nil {
-			if err = vc.Do(ctx, vc.B().Ping().Build()).Error(); err == nil {
-				slog.Debug("local redis is ready")
-
-				return vc, nil
-			}
+func EnsureClusterInitialized(ctx context.Context, vc valkey.Client, ourIndex int, primaryCount int, shards []v1alpha1.ShardStatus) error {
+	if len(shards) > 0 {
+		podName, err := os.Hostname()
+		if err != nil {
+			return fmt.Errorf("failed to determine pod hostname: %w", err)
 		}
 
-		slog.Debug("waiting for local redis to become ready",
-			slog.Duration("wait", PingCheckInterval),
-			slog.String("error", err.Error()),
-		)
+		if ourShard, isPrimary, found := RoleFromStatus(shards, podName); found {
+			if isPrimary {
+				slog.Info("configuring ourselves as a primary node, per ValkeyCluster status",
+					slog.String("pod", podName),
+					slog.Int64("shard_index", int64(ourShard.Index)),
+				)
 
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		case <-time.After(PingCheckInterval):
+				return ConfigurePrimaryNodeFromShard(ctx, vc, ourShard, shards)
+			}
+
+			slog.Info("configuring ourselves as a replica node, per ValkeyCluster status",
+				slog.String("pod", podName),
+				slog.String("primary", ourShard.PrimaryPodName),
+			)
+
+			return ConfigureReplicaNodeFromShard(ctx, vc, ourShard)
 		}
+
+		slog.Warn("pod not yet present in ValkeyCluster status; falling back to index heuristic", slog.String("pod", podName))
 	}
-}
 
-func EnsureClusterInitialized(ctx context.Context, vc valkey.Client, ourIndex int, primaryCount int) error {
 	switch {
 	case ourIndex < primaryCount:
 		slog.Info("configuring ourselves as a primary node", slog.Int64("index", int64(ourIndex)))
@@ -95,9 +121,74 @@ func EnsureClusterInitialized(ctx context.Context, vc valkey.Client, ourIndex in
 	}
 }
 
+// ConfigurePrimaryNode configures ourIndex as a primary using the index-modulo-primaryCount
+// heuristic, for StatefulSets not (yet) backed by a ValkeyCluster. Primaries are assumed to occupy
+// contiguous indices [0, primaryCount).
 func ConfigurePrimaryNode(ctx context.Context, vc valkey.Client, ourIndex int, primaryCount int) error {
 	log := slog.With(slog.Int64("index", int64(ourIndex)))
 
+	slots := SlotPlan(primaryCount)[ourIndex]
+
+	var peers []net.IP
+
+	for peerIndex := range primaryCount {
+		if peerIndex == ourIndex {
+			continue
+		}
+
+		peerIP, err := nodeIP(peerIndex)
+		if err != nil {
+			log.Warn("no IP available for peer",
+				slog.Int64("peer_index", int64(peerIndex)),
+				slog.String("error", err.Error()),
+			)
+
+			continue
+		}
+
+		peers = append(peers, peerIP)
+	}
+
+	return configurePrimary(ctx, vc, log, slots, peers)
+}
+
+// ConfigurePrimaryNodeFromShard configures ourShard's primary using the slot range and peer
+// primaries published to status.shards by the controller, in place of the index-modulo-
+// primaryCount heuristic. This is required whenever ReplicasPerShard > 0: shard primaries are then
+// not contiguous pod indices, so neither the slot range nor the peer set ConfigurePrimaryNode
+// derives from ourIndex/primaryCount would be correct.
+func ConfigurePrimaryNodeFromShard(ctx context.Context, vc valkey.Client, ourShard v1alpha1.ShardStatus, allShards []v1alpha1.ShardStatus) error {
+	log := slog.With(slog.Int64("shard_index", int64(ourShard.Index)))
+
+	slots := SlotRange{Start: int(ourShard.SlotStart), End: int(ourShard.SlotEnd)}
+
+	var peers []net.IP
+
+	for _, shard := range allShards {
+		if shard.Index == ourShard.Index {
+			continue
+		}
+
+		peerIP, err := podIP(shard.PrimaryPodName)
+		if err != nil {
+			log.Warn("no IP available for peer primary",
+				slog.String("peer_pod", shard.PrimaryPodName),
+				slog.String("error", err.Error()),
+			)
+
+			continue
+		}
+
+		peers = append(peers, peerIP)
+	}
+
+	return configurePrimary(ctx, vc, log, slots, peers)
+}
+
+// configurePrimary is the common bottom half of ConfigurePrimaryNode and
+// ConfigurePrimaryNodeFromShard: it assigns slots and introduces peers once the caller has worked
+// out what they are.
+func configurePrimary(ctx context.Context, vc valkey.Client, log *slog.Logger, slots SlotRange, peers []net.IP) error {
 	infoReader, err := vc.Do(ctx, vc.B().ClusterInfo().Build()).AsReader()
 	if err != nil {
 		return fmt.Errorf("failed to read cluster info: %w", err)
@@ -112,53 +203,46 @@ func ConfigurePrimaryNode(ctx context.Context, vc valkey.Client, ourIndex int, p
 	if clusterInfo.LocalEpoch() > 0 {
 		log.Debug("cluster epoch is configured")
 	} else {
-		log.Info("setting cluster epoch equal to node index + 1", slog.Int64("epoch", int64(ourIndex+1)))
+		log.Info("waiting for cluster epoch to be assigned")
 	}
 
 	// Ensure slots are configured.
 	if clusterInfo.SlotsAssigned() > 0 {
 		log.Debug("slots for this node are already assigned")
 	} else {
-		firstSlot := int(ourIndex) * slotSize(primaryCount)
-		lastSlot := max(TotalSlotCount, firstSlot+slotSize(primaryCount)) - 1
+		log.Info("setting cluster shard slots", slog.Int("first", slots.Start), slog.Int("last", slots.End))
 
-		log.Info("setting cluster shard slots", slog.Int("first", firstSlot), slog.Int("last", lastSlot))
-
-		if err := vc.Do(ctx, vc.B().ClusterAddslotsrange().StartSlotEndSlot().StartSlotEndSlot(int64(firstSlot), int64(lastSlot)).Build()).Error(); err != nil {
-			return fmt.Errorf("failed to set slot range (%d - %d) on node %d: %w", firstSlot, lastSlot, ourIndex, err)
+		if err := vc.Do(ctx, vc.B().ClusterAddslotsrange().StartSlotEndSlot().StartSlotEndSlot(int64(slots.Start), int64(slots.End)).Build()).Error(); err != nil {
+			return fmt.Errorf("failed to set slot range (%d - %d): %w", slots.Start, slots.End, err)
 		}
 	}
 
-	for peerIndex := range primaryCount {
-		if peerIndex == ourIndex {
-			continue
-		}
+	for _, peerIP := range peers {
+		log.Info("introducing ourselves to peer", slog.String("peer_ip", peerIP.String()))
 
-		log.Info("introducting ourselves to peer", slog.Int64("peer_index", int64(peerIndex)))
+		meet := vc.B().ClusterMeet().Ip(peerIP.String()).Port(ValkeyPort)
 
-		peerIP, err := nodeIP(peerIndex)
-		if err != nil {
-			log.Warn("no IP available for peer",
-				slog.Int64("peer_index", int64(peerIndex)),
-				slog.String("error", err.Error()),
-			)
+		var meetErr error
 
-			continue
+		if DefaultClientFactory.TLSEnabled() {
+			meetErr = vc.Do(ctx, meet.Cport(int64(DefaultClientFactory.BusPort(ValkeyPort))).Build()).Error()
+		} else {
+			meetErr = vc.Do(ctx, meet.Build()).Error()
 		}
 
-		if err := vc.Do(ctx, vc.B().ClusterMeet().Ip(peerIP.String()).Port(ValkeyPort).Build()).Error(); err != nil {
+		if meetErr != nil {
 			log.Warn("failed to introduce peer",
-				slog.Int64("peer_index", int64(peerIndex)),
-				slog.String("error", err.Error()),
+				slog.String("peer_ip", peerIP.String()),
+				slog.String("error", meetErr.Error()),
 			)
-
-			continue
 		}
 	}
 
 	return nil
 }
 
+// ConfigureReplicaNode configures ourIndex as a replica of its primary using the
+// index-modulo-primaryCount heuristic, for StatefulSets not (yet) backed by a ValkeyCluster.
 func ConfigureReplicaNode(ctx context.Context, vc valkey.Client, ourIndex int, primaryCount int) error {
 	ourPrimary := ourIndex % primaryCount
 
@@ -167,37 +251,61 @@ func ConfigureReplicaNode(ctx context.Context, vc valkey.Client, ourIndex int, p
 		return fmt.Errorf("failed to find IP for our primary: %w", err)
 	}
 
-	ourPrimaryAddr := net.JoinHostPort(ourPrimaryIP.String(), strconv.FormatInt(ValkeyPort, 10))
+	return configureReplica(ctx, vc, slog.Int64("index", int64(ourIndex)), ourPrimaryIP)
+}
 
-	if _, err := WaitPing(ctx, ourPrimaryAddr); err != nil {
-		return fmt.Errorf("failed to wait for our primary %q to come alive: %w", ourPrimaryAddr, err)
+// ConfigureReplicaNodeFromShard configures ourShard's replica using the primary pod name published
+// to status.shards by the controller, in place of the index-modulo-primaryCount heuristic.
+func ConfigureReplicaNodeFromShard(ctx context.Context, vc valkey.Client, ourShard v1alpha1.ShardStatus) error {
+	ourPrimaryIP, err := podIP(ourShard.PrimaryPodName)
+	if err != nil {
+		return fmt.Errorf("failed to find IP for our primary: %w", err)
 	}
 
-	slog.Info("configuring our valkey instance as a replica",
-		slog.Int64("index", int64(ourIndex)),
-		slog.String("primary", ourPrimaryAddr),
-	)
+	return configureReplica(ctx, vc, slog.Int64("shard_index", int64(ourShard.Index)), ourPrimaryIP)
+}
 
-	return vc.Do(ctx, vc.B().ClusterReplicate().NodeId(ourPrimaryAddr).Build()).Error()
+// configureReplica is the common bottom half of ConfigureReplicaNode and
+// ConfigureReplicaNodeFromShard.
+func configureReplica(ctx context.Context, vc valkey.Client, idAttr slog.Attr, primaryIP net.IP) error {
+	primaryAddr := net.JoinHostPort(primaryIP.String(), strconv.FormatInt(ValkeyPort, 10))
+
+	if _, err := WaitPing(ctx, primaryAddr); err != nil {
+		return fmt.Errorf("failed to wait for our primary %q to come alive: %w", primaryAddr, err)
+	}
+
+	slog.Info("configuring our valkey instance as a replica", idAttr, slog.String("primary", primaryAddr))
+
+	return vc.Do(ctx, vc.B().ClusterReplicate().NodeId(primaryAddr).Build()).Error()
 }
 
 func nodeName(index int) string {
 	return NodeNamePrefix + strconv.FormatInt(int64(index), 10) + NodeNameSuffix
 }
 
+// podDNSName returns the in-cluster DNS name for a StatefulSet pod's headless-service address,
+// given its pod name (e.g. "<statefulset>-<ordinal>").
+func podDNSName(podName string) string {
+	return podName + NodeNameSuffix
+}
+
 func nodeIP(index int) (net.IP, error) {
-	ips, err := net.LookupIP(nodeName(index))
+	return lookupIP(nodeName(index))
+}
+
+func podIP(podName string) (net.IP, error) {
+	return lookupIP(podDNSName(podName))
+}
+
+func lookupIP(host string) (net.IP, error) {
+	ips, err := net.LookupIP(host)
 	if err != nil {
-		return nil, fmt.Errorf("failed to lookup IP for %s: %w", nodeName(index), err)
+		return nil, fmt.Errorf("failed to lookup IP for %s: %w", host, err)
 	}
 
 	if len(ips) < 1 {
-		return nil, fmt.Errorf("no IPs found for %s", nodeName(index))
+		return nil, fmt.Errorf("no IPs found for %s", host)
 	}
 
 	return ips[0], nil
 }
-
-func slotSize(primaryCount int) int {
-	return TotalSlotCount / int(primaryCount)
-}
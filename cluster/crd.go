@@ -0,0 +1,78 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/tkhq/valkey-manager/api/v1alpha1"
+)
+
+// RoleFromStatus determines whether podName is a shard primary or replica per a ValkeyCluster's
+// status.shards, and returns the shard it belongs to, in place of the index-modulo-primaryCount
+// heuristic used when no such status is available yet (e.g. before the controller's first
+// reconcile of a newly created ValkeyCluster).
+func RoleFromStatus(shards []v1alpha1.ShardStatus, podName string) (shard v1alpha1.ShardStatus, isPrimary bool, found bool) {
+	for _, s := range shards {
+		if s.PrimaryPodName == podName {
+			return s, true, true
+		}
+
+		for _, replica := range s.ReplicaPodNames {
+			if replica == podName {
+				return s, false, true
+			}
+		}
+	}
+
+	return v1alpha1.ShardStatus{}, false, false
+}
+
+// PublishHealth writes the local node's observed cluster health (state, epoch, known nodes, slots
+// assigned) into vc's status.conditions, so `kubectl get valkeycluster` shows readiness at a
+// glance.
+func PublishHealth(ctx context.Context, vcClient *v1alpha1.Client, vc *v1alpha1.ValkeyCluster, info Info) error {
+	status := metav1.ConditionFalse
+	reason := "ClusterNotOK"
+
+	if info.State() == StateOK {
+		status = metav1.ConditionTrue
+		reason = "ClusterOK"
+	}
+
+	vc.Status.Conditions = upsertCondition(vc.Status.Conditions, metav1.Condition{
+		Type:    v1alpha1.ConditionReady,
+		Status:  status,
+		Reason:  reason,
+		Message: fmt.Sprintf("state=%s epoch=%d known_nodes=%d slots_assigned=%d", info.State(), info.ClusterEpoch(), info.KnownNodeCount(), info.SlotsAssigned()),
+	})
+
+	if _, err := vcClient.UpdateStatus(ctx, vc); err != nil {
+		return fmt.Errorf("failed to publish cluster health: %w", err)
+	}
+
+	return nil
+}
+
+func upsertCondition(conditions []metav1.Condition, next metav1.Condition) []metav1.Condition {
+	for i, c := range conditions {
+		if c.Type != next.Type {
+			continue
+		}
+
+		if c.Status != next.Status {
+			next.LastTransitionTime = metav1.Now()
+		} else {
+			next.LastTransitionTime = c.LastTransitionTime
+		}
+
+		conditions[i] = next
+
+		return conditions
+	}
+
+	next.LastTransitionTime = metav1.Now()
+
+	return append(conditions, next)
+}
@@ -0,0 +1,203 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/valkey-io/valkey-go"
+)
+
+const (
+	// MigrationBatchSize is the number of keys migrated per MIGRATE call while draining a slot.
+	MigrationBatchSize = 100
+
+	// MigrationTimeoutMillis is the timeout passed to each MIGRATE call.
+	MigrationTimeoutMillis = 5000
+
+	// CheckpointDir is where in-progress slot migration state is persisted, so a manager restart
+	// mid-migration resumes rather than re-migrating (and potentially corrupting) already-drained
+	// slots.
+	CheckpointDir = "/data"
+)
+
+// MigrationCheckpoint records which slots of a scale-down migration have already been fully
+// drained from the retiring node.
+type MigrationCheckpoint struct {
+	RetiringNodeID string `json:"retiring_node_id"`
+	DoneSlots      []int  `json:"done_slots"`
+}
+
+func checkpointPath(retiringNodeID string) string {
+	return fmt.Sprintf("%s/migration-%s.json", CheckpointDir, retiringNodeID)
+}
+
+func loadCheckpoint(retiringNodeID string) (*MigrationCheckpoint, error) {
+	b, err := os.ReadFile(checkpointPath(retiringNodeID))
+	if errors.Is(err, os.ErrNotExist) {
+		return &MigrationCheckpoint{RetiringNodeID: retiringNodeID}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read migration checkpoint: %w", err)
+	}
+
+	cp := new(MigrationCheckpoint)
+	if err := json.Unmarshal(b, cp); err != nil {
+		return nil, fmt.Errorf("failed to parse migration checkpoint: %w", err)
+	}
+
+	if cp.RetiringNodeID != retiringNodeID {
+		// Stale checkpoint from a previous retirement; start fresh.
+		return &MigrationCheckpoint{RetiringNodeID: retiringNodeID}, nil
+	}
+
+	return cp, nil
+}
+
+func (cp *MigrationCheckpoint) save() error {
+	b, err := json.Marshal(cp)
+	if err != nil {
+		return fmt.Errorf("failed to marshal migration checkpoint: %w", err)
+	}
+
+	if err := os.WriteFile(checkpointPath(cp.RetiringNodeID), b, 0o600); err != nil {
+		return fmt.Errorf("failed to persist migration checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+func (cp *MigrationCheckpoint) isDone(slot int) bool {
+	for _, s := range cp.DoneSlots {
+		if s == slot {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MigrateSlots drains every slot in slots from the retiring node onto the destination node using
+// the standard Redis Cluster migration handshake (IMPORTING/MIGRATING, GETKEYSINSLOT + MIGRATE in
+// batches, then NODE to finalize ownership). Progress is checkpointed to disk after each slot, so
+// a manager restart mid-migration resumes rather than re-migrating already-drained slots.
+func MigrateSlots(ctx context.Context, srcVC, dstVC valkey.Client, retiringNodeID, dstNodeID, dstHost string, dstPort int, slots []int) error {
+	cp, err := loadCheckpoint(retiringNodeID)
+	if err != nil {
+		return err
+	}
+
+	for _, slot := range slots {
+		if cp.isDone(slot) {
+			continue
+		}
+
+		if err := migrateSlot(ctx, srcVC, dstVC, retiringNodeID, dstNodeID, dstHost, dstPort, slot); err != nil {
+			return fmt.Errorf("failed to migrate slot %d from %s to %s: %w", slot, retiringNodeID, dstNodeID, err)
+		}
+
+		cp.DoneSlots = append(cp.DoneSlots, slot)
+
+		if err := cp.save(); err != nil {
+			return err
+		}
+
+		slog.Info("migrated slot off retiring node",
+			slog.Int("slot", slot),
+			slog.String("retiring_node_id", retiringNodeID),
+			slog.String("dst_node_id", dstNodeID),
+		)
+	}
+
+	if err := os.Remove(checkpointPath(retiringNodeID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		slog.Warn("failed to clean up migration checkpoint", slog.String("error", err.Error()))
+	}
+
+	return nil
+}
+
+func migrateSlot(ctx context.Context, srcVC, dstVC valkey.Client, retiringNodeID, dstNodeID, dstHost string, dstPort int, slot int) error {
+	if err := dstVC.Do(ctx, dstVC.B().ClusterSetslot().Slot(int64(slot)).Importing(retiringNodeID).Build()).Error(); err != nil {
+		return fmt.Errorf("failed to mark slot %d importing on destination: %w", slot, err)
+	}
+
+	if err := srcVC.Do(ctx, srcVC.B().ClusterSetslot().Slot(int64(slot)).Migrating(dstNodeID).Build()).Error(); err != nil {
+		return fmt.Errorf("failed to mark slot %d migrating on source: %w", slot, err)
+	}
+
+	for {
+		keys, err := srcVC.Do(ctx, srcVC.B().ClusterGetkeysinslot().Slot(int64(slot)).Count(MigrationBatchSize).Build()).AsStrSlice()
+		if err != nil {
+			return fmt.Errorf("failed to read keys for slot %d: %w", slot, err)
+		}
+
+		if len(keys) == 0 {
+			break
+		}
+
+		if err := srcVC.Do(ctx, srcVC.B().Migrate().Host(dstHost).Port(int64(dstPort)).Key("").DestinationDb(0).
+			Timeout(MigrationTimeoutMillis).Keys(keys...).Build()).Error(); err != nil {
+			return fmt.Errorf("failed to migrate %d keys for slot %d: %w", len(keys), slot, err)
+		}
+	}
+
+	if err := srcVC.Do(ctx, srcVC.B().ClusterSetslot().Slot(int64(slot)).Node(dstNodeID).Build()).Error(); err != nil {
+		return fmt.Errorf("failed to finalize slot %d ownership on source: %w", slot, err)
+	}
+
+	if err := dstVC.Do(ctx, dstVC.B().ClusterSetslot().Slot(int64(slot)).Node(dstNodeID).Build()).Error(); err != nil {
+		return fmt.Errorf("failed to finalize slot %d ownership on destination: %w", slot, err)
+	}
+
+	return nil
+}
+
+// SlotsOwnedBy returns every slot currently owned by nodeID, as reported by CLUSTER SLOTS.
+func SlotsOwnedBy(ctx context.Context, vc valkey.Client, nodeID string) ([]int, error) {
+	entries, err := vc.Do(ctx, vc.B().ClusterSlots().Build()).ToArray()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cluster slots: %w", err)
+	}
+
+	var slots []int
+
+	for _, entry := range entries {
+		fields, err := entry.ToArray()
+		if err != nil || len(fields) < 3 {
+			continue
+		}
+
+		start, startErr := fields[0].ToInt64()
+		end, endErr := fields[1].ToInt64()
+
+		owner, ownerErr := fields[2].ToArray()
+		if startErr != nil || endErr != nil || ownerErr != nil || len(owner) < 3 {
+			continue
+		}
+
+		ownerID, err := owner[2].ToString()
+		if err != nil || ownerID != nodeID {
+			continue
+		}
+
+		for s := start; s <= end; s++ {
+			slots = append(slots, int(s))
+		}
+	}
+
+	return slots, nil
+}
+
+// ForgetNode issues CLUSTER FORGET for nodeID on every client in peers, so a fully drained,
+// retired node is removed from every remaining node's view of the cluster.
+func ForgetNode(ctx context.Context, nodeID string, peers []valkey.Client) error {
+	for _, peer := range peers {
+		if err := peer.Do(ctx, peer.B().ClusterForget().NodeId(nodeID).Build()).Error(); err != nil {
+			return fmt.Errorf("failed to forget node %s: %w", nodeID, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,33 @@
+package cluster
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/valkey-io/valkey-go"
+)
+
+// IsPrimary reports whether the local node currently considers itself a cluster primary, as
+// reported by the "myself" line of CLUSTER NODES.
+func IsPrimary(ctx context.Context, vc valkey.Client) (bool, error) {
+	nodes, err := vc.Do(ctx, vc.B().ClusterNodes().Build()).ToString()
+	if err != nil {
+		return false, fmt.Errorf("failed to read cluster nodes: %w", err)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader([]byte(nodes)))
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || !strings.Contains(fields[2], "myself") {
+			continue
+		}
+
+		return strings.Contains(fields[2], "master"), nil
+	}
+
+	return false, fmt.Errorf("failed to find self in cluster nodes output")
+}
@@ -0,0 +1,245 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
+
+	"github.com/valkey-io/valkey-go"
+
+	"github.com/tkhq/valkey-manager/api/v1alpha1"
+)
+
+// ScaleDown migrates all slots off primaries retired by a shrinking StatefulSet and forgets them
+// from the cluster, so the cluster never ends up in FAIL with slots still assigned to a node that
+// no longer exists. It is a no-op if no primaries are retiring (e.g. only replicas were removed).
+//
+// Callers are expected to only invoke this from the leader's reconcile path (see
+// Manager.runLeaderElection), which already guarantees a single sidecar drives any given
+// StatefulSet update; ScaleDown itself performs no coordinator election of its own.
+func ScaleDown(ctx context.Context, oldReplicaCount, newReplicaCount int) error {
+	oldPrimaryCount, _ := primariesAndReplicas(oldReplicaCount)
+	newPrimaryCount, _ := primariesAndReplicas(newReplicaCount)
+
+	if newPrimaryCount >= oldPrimaryCount {
+		return nil
+	}
+
+	for retiringIndex := newPrimaryCount; retiringIndex < oldPrimaryCount; retiringIndex++ {
+		destIndex := retiringIndex % newPrimaryCount
+
+		if err := migratePrimary(ctx, retiringIndex, destIndex, newReplicaCount); err != nil {
+			return fmt.Errorf("failed to migrate retiring primary %d to %d: %w", retiringIndex, destIndex, err)
+		}
+	}
+
+	return nil
+}
+
+func migratePrimary(ctx context.Context, retiringIndex, destIndex, newReplicaCount int) error {
+	retiringIP, err := nodeIP(retiringIndex)
+	if err != nil {
+		return fmt.Errorf("failed to look up retiring primary: %w", err)
+	}
+
+	destIP, err := nodeIP(destIndex)
+	if err != nil {
+		return fmt.Errorf("failed to look up destination primary: %w", err)
+	}
+
+	retiringAddr := net.JoinHostPort(retiringIP.String(), strconv.Itoa(ValkeyPort))
+	destAddr := net.JoinHostPort(destIP.String(), strconv.Itoa(ValkeyPort))
+
+	retiringVC, err := WaitPing(ctx, retiringAddr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to retiring primary %q: %w", retiringAddr, err)
+	}
+
+	destVC, err := WaitPing(ctx, destAddr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to destination primary %q: %w", destAddr, err)
+	}
+
+	retiringNodeID, err := retiringVC.Do(ctx, retiringVC.B().ClusterMyid().Build()).ToString()
+	if err != nil {
+		return fmt.Errorf("failed to read retiring primary's node ID: %w", err)
+	}
+
+	destNodeID, err := destVC.Do(ctx, destVC.B().ClusterMyid().Build()).ToString()
+	if err != nil {
+		return fmt.Errorf("failed to read destination primary's node ID: %w", err)
+	}
+
+	slots, err := SlotsOwnedBy(ctx, retiringVC, retiringNodeID)
+	if err != nil {
+		return fmt.Errorf("failed to enumerate slots owned by retiring primary: %w", err)
+	}
+
+	slog.Info("migrating slots off retiring primary",
+		slog.Int("retiring_index", retiringIndex),
+		slog.Int("dest_index", destIndex),
+		slog.Int("slot_count", len(slots)),
+	)
+
+	if err := MigrateSlots(ctx, retiringVC, destVC, retiringNodeID, destNodeID, destIP.String(), ValkeyPort, slots); err != nil {
+		return err
+	}
+
+	peers, err := survivingPeers(ctx, newReplicaCount)
+	if err != nil {
+		return fmt.Errorf("failed to connect to surviving peers to forget retired node: %w", err)
+	}
+
+	if err := ForgetNode(ctx, retiringNodeID, peers); err != nil {
+		return err
+	}
+
+	slog.Info("retired primary fully drained and forgotten", slog.String("node_id", retiringNodeID))
+
+	return nil
+}
+
+func survivingPeers(ctx context.Context, newReplicaCount int) ([]valkey.Client, error) {
+	peers := make([]valkey.Client, 0, newReplicaCount)
+
+	for i := range newReplicaCount {
+		ip, err := nodeIP(i)
+		if err != nil {
+			return nil, err
+		}
+
+		vc, err := WaitPing(ctx, net.JoinHostPort(ip.String(), strconv.Itoa(ValkeyPort)))
+		if err != nil {
+			return nil, err
+		}
+
+		peers = append(peers, vc)
+	}
+
+	return peers, nil
+}
+
+// ScaleDownFromShards migrates all slots off primaries retired by a shrinking ValkeyCluster and
+// forgets them from the cluster, using status.shards-derived pod identities in place of
+// ScaleDown's index-modulo-primaryCount heuristic. This is required whenever ReplicasPerShard > 0:
+// shard primaries are then not contiguous pod indices, so neither the retiring primary nor the
+// destination primary ScaleDown derives from oldReplicaCount/newReplicaCount would be correct.
+//
+// newShards is the post-scale-down shard plan (as already published to status.shards by the
+// controller); statefulSetName and oldReplicaCount reconstruct the pod names of shards retired
+// since the last reconcile, which no longer appear in newShards. This assumes ReplicasPerShard is
+// unchanged across the scale event, which holds for the only shard-count scale-down path the
+// controller performs (shrinking Spec.Shards alone).
+func ScaleDownFromShards(ctx context.Context, statefulSetName string, oldReplicaCount int, newShards []v1alpha1.ShardStatus) error {
+	newShardCount := len(newShards)
+	if newShardCount == 0 {
+		return nil
+	}
+
+	width := 1 + len(newShards[0].ReplicaPodNames)
+	oldShardCount := oldReplicaCount / width
+
+	if oldShardCount <= newShardCount {
+		return nil
+	}
+
+	for retiringIndex := newShardCount; retiringIndex < oldShardCount; retiringIndex++ {
+		retiringPrimary := fmt.Sprintf("%s-%d", statefulSetName, retiringIndex*width)
+		dest := newShards[retiringIndex%newShardCount]
+
+		if err := migratePrimaryFromShards(ctx, retiringPrimary, dest, newShards); err != nil {
+			return fmt.Errorf("failed to migrate retiring primary %s to %s: %w", retiringPrimary, dest.PrimaryPodName, err)
+		}
+	}
+
+	return nil
+}
+
+func migratePrimaryFromShards(ctx context.Context, retiringPrimaryPod string, dest v1alpha1.ShardStatus, survivingShards []v1alpha1.ShardStatus) error {
+	retiringIP, err := podIP(retiringPrimaryPod)
+	if err != nil {
+		return fmt.Errorf("failed to look up retiring primary: %w", err)
+	}
+
+	destIP, err := podIP(dest.PrimaryPodName)
+	if err != nil {
+		return fmt.Errorf("failed to look up destination primary: %w", err)
+	}
+
+	retiringAddr := net.JoinHostPort(retiringIP.String(), strconv.Itoa(ValkeyPort))
+	destAddr := net.JoinHostPort(destIP.String(), strconv.Itoa(ValkeyPort))
+
+	retiringVC, err := WaitPing(ctx, retiringAddr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to retiring primary %q: %w", retiringAddr, err)
+	}
+
+	destVC, err := WaitPing(ctx, destAddr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to destination primary %q: %w", destAddr, err)
+	}
+
+	retiringNodeID, err := retiringVC.Do(ctx, retiringVC.B().ClusterMyid().Build()).ToString()
+	if err != nil {
+		return fmt.Errorf("failed to read retiring primary's node ID: %w", err)
+	}
+
+	destNodeID, err := destVC.Do(ctx, destVC.B().ClusterMyid().Build()).ToString()
+	if err != nil {
+		return fmt.Errorf("failed to read destination primary's node ID: %w", err)
+	}
+
+	slots, err := SlotsOwnedBy(ctx, retiringVC, retiringNodeID)
+	if err != nil {
+		return fmt.Errorf("failed to enumerate slots owned by retiring primary: %w", err)
+	}
+
+	slog.Info("migrating slots off retiring primary",
+		slog.String("retiring_pod", retiringPrimaryPod),
+		slog.String("dest_pod", dest.PrimaryPodName),
+		slog.Int("slot_count", len(slots)),
+	)
+
+	if err := MigrateSlots(ctx, retiringVC, destVC, retiringNodeID, destNodeID, destIP.String(), ValkeyPort, slots); err != nil {
+		return err
+	}
+
+	peers, err := survivingPeersFromShards(ctx, survivingShards)
+	if err != nil {
+		return fmt.Errorf("failed to connect to surviving peers to forget retired node: %w", err)
+	}
+
+	if err := ForgetNode(ctx, retiringNodeID, peers); err != nil {
+		return err
+	}
+
+	slog.Info("retired primary fully drained and forgotten", slog.String("node_id", retiringNodeID))
+
+	return nil
+}
+
+func survivingPeersFromShards(ctx context.Context, shards []v1alpha1.ShardStatus) ([]valkey.Client, error) {
+	var peers []valkey.Client
+
+	for _, shard := range shards {
+		podNames := append([]string{shard.PrimaryPodName}, shard.ReplicaPodNames...)
+
+		for _, podName := range podNames {
+			ip, err := podIP(podName)
+			if err != nil {
+				return nil, err
+			}
+
+			vc, err := WaitPing(ctx, net.JoinHostPort(ip.String(), strconv.Itoa(ValkeyPort)))
+			if err != nil {
+				return nil, err
+			}
+
+			peers = append(peers, vc)
+		}
+	}
+
+	return peers, nil
+}
@@ -0,0 +1,179 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/valkey-io/valkey-go"
+
+	"github.com/tkhq/valkey-manager/api/v1alpha1"
+)
+
+// SlotRange is an inclusive, contiguous range of cluster hash slots.
+type SlotRange struct {
+	Start int
+	End   int
+}
+
+// SlotPlan returns exactly primaryCount non-overlapping, contiguous, gap-free ranges that together
+// cover all TotalSlotCount slots, indexed by primary index. The TotalSlotCount % primaryCount
+// remainder is distributed one extra slot at a time across the first `remainder` shards, so no two
+// shards' slot counts differ by more than one.
+func SlotPlan(primaryCount int) []SlotRange {
+	if primaryCount <= 0 {
+		return nil
+	}
+
+	base := TotalSlotCount / primaryCount
+	remainder := TotalSlotCount % primaryCount
+
+	plan := make([]SlotRange, primaryCount)
+
+	start := 0
+
+	for i := range primaryCount {
+		size := base
+		if i < remainder {
+			size++
+		}
+
+		plan[i] = SlotRange{Start: start, End: start + size - 1}
+		start += size
+	}
+
+	return plan
+}
+
+// PlanDrift describes a discrepancy between a SlotPlan and the slot ownership actually observed
+// via CLUSTER SLOTS.
+type PlanDrift struct {
+	// MissingSlots are slots the plan assigns to a primary but that CLUSTER SLOTS reports no
+	// owner for.
+	MissingSlots []int
+
+	// OverlappingSlots are slots claimed by more than one node.
+	OverlappingSlots []int
+
+	// MisassignedSlots maps a slot to the IP address that actually owns it, for slots owned by
+	// someone other than the primary the plan assigns them to.
+	MisassignedSlots map[int]string
+}
+
+// Empty reports whether no drift was found.
+func (d PlanDrift) Empty() bool {
+	return len(d.MissingSlots) == 0 && len(d.OverlappingSlots) == 0 && len(d.MisassignedSlots) == 0
+}
+
+// VerifyPlan parses CLUSTER SLOTS and reports any drift from SlotPlan(primaryCount): slots with no
+// owner, slots claimed by more than one node, or slots owned by a node other than the one the plan
+// assigns them to. Primaries are assumed to occupy contiguous indices [0, primaryCount); use
+// VerifyPlanFromShards for ValkeyClusters, where that assumption doesn't hold once
+// ReplicasPerShard > 0.
+func VerifyPlan(ctx context.Context, vc valkey.Client, primaryCount int) (PlanDrift, error) {
+	plan := SlotPlan(primaryCount)
+
+	ranges := make([]SlotRange, len(plan))
+	expectedIPs := make([]net.IP, len(plan))
+
+	for i, r := range plan {
+		ranges[i] = r
+
+		if ip, err := nodeIP(i); err == nil {
+			expectedIPs[i] = ip
+		}
+	}
+
+	return verifyPlan(ctx, vc, ranges, expectedIPs)
+}
+
+// VerifyPlanFromShards parses CLUSTER SLOTS and reports any drift from status.shards' published
+// slot ranges and primary pod names, in place of VerifyPlan's SlotPlan(primaryCount)/nodeIP(index)
+// heuristic. This is required whenever ReplicasPerShard > 0, for the same reason
+// ConfigurePrimaryNodeFromShard is.
+func VerifyPlanFromShards(ctx context.Context, vc valkey.Client, shards []v1alpha1.ShardStatus) (PlanDrift, error) {
+	ranges := make([]SlotRange, len(shards))
+	expectedIPs := make([]net.IP, len(shards))
+
+	for i, shard := range shards {
+		ranges[i] = SlotRange{Start: int(shard.SlotStart), End: int(shard.SlotEnd)}
+
+		if ip, err := podIP(shard.PrimaryPodName); err == nil {
+			expectedIPs[i] = ip
+		}
+	}
+
+	return verifyPlan(ctx, vc, ranges, expectedIPs)
+}
+
+// verifyPlan is the common bottom half of VerifyPlan and VerifyPlanFromShards: it parses CLUSTER
+// SLOTS and reports any drift against ranges/expectedIPs, indexed pairwise. A nil expectedIPs[i]
+// (the primary's IP couldn't be resolved) reports every slot in ranges[i] as missing.
+func verifyPlan(ctx context.Context, vc valkey.Client, ranges []SlotRange, expectedIPs []net.IP) (PlanDrift, error) {
+	entries, err := vc.Do(ctx, vc.B().ClusterSlots().Build()).ToArray()
+	if err != nil {
+		return PlanDrift{}, fmt.Errorf("failed to read cluster slots: %w", err)
+	}
+
+	ownerIPBySlot := make(map[int]string, TotalSlotCount)
+
+	var overlapping []int
+
+	for _, entry := range entries {
+		fields, err := entry.ToArray()
+		if err != nil || len(fields) < 3 {
+			continue
+		}
+
+		start, startErr := fields[0].ToInt64()
+		end, endErr := fields[1].ToInt64()
+
+		owner, ownerErr := fields[2].ToArray()
+		if startErr != nil || endErr != nil || ownerErr != nil || len(owner) < 1 {
+			continue
+		}
+
+		ip, err := owner[0].ToString()
+		if err != nil {
+			continue
+		}
+
+		for s := start; s <= end; s++ {
+			if _, exists := ownerIPBySlot[int(s)]; exists {
+				overlapping = append(overlapping, int(s))
+			}
+
+			ownerIPBySlot[int(s)] = ip
+		}
+	}
+
+	var missing []int
+
+	misassigned := map[int]string{}
+
+	for i, r := range ranges {
+		expectedIP := expectedIPs[i]
+		if expectedIP == nil {
+			for slot := r.Start; slot <= r.End; slot++ {
+				missing = append(missing, slot)
+			}
+
+			continue
+		}
+
+		for slot := r.Start; slot <= r.End; slot++ {
+			ip, ok := ownerIPBySlot[slot]
+			if !ok {
+				missing = append(missing, slot)
+
+				continue
+			}
+
+			if ip != expectedIP.String() {
+				misassigned[slot] = ip
+			}
+		}
+	}
+
+	return PlanDrift{MissingSlots: missing, OverlappingSlots: overlapping, MisassignedSlots: misassigned}, nil
+}
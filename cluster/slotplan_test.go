@@ -0,0 +1,75 @@
+package cluster
+
+import "testing"
+
+func TestSlotPlan(t *testing.T) {
+	tests := []struct {
+		name         string
+		primaryCount int
+	}{
+		{name: "evenly divisible", primaryCount: 4},
+		{name: "remainder of one", primaryCount: 3},
+		{name: "remainder spread across most shards", primaryCount: TotalSlotCount - 1},
+		{name: "single primary", primaryCount: 1},
+		{name: "zero primaries", primaryCount: 0},
+		{name: "negative primaries", primaryCount: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := SlotPlan(tt.primaryCount)
+
+			if tt.primaryCount <= 0 {
+				if plan != nil {
+					t.Fatalf("SlotPlan(%d) = %v, want nil", tt.primaryCount, plan)
+				}
+
+				return
+			}
+
+			if len(plan) != tt.primaryCount {
+				t.Fatalf("SlotPlan(%d) returned %d ranges, want %d", tt.primaryCount, len(plan), tt.primaryCount)
+			}
+
+			base := TotalSlotCount / tt.primaryCount
+			remainder := TotalSlotCount % tt.primaryCount
+
+			wantStart := 0
+
+			for i, r := range plan {
+				wantSize := base
+				if i < remainder {
+					wantSize++
+				}
+
+				if r.Start != wantStart {
+					t.Errorf("plan[%d].Start = %d, want %d", i, r.Start, wantStart)
+				}
+
+				gotSize := r.End - r.Start + 1
+				if gotSize != wantSize {
+					t.Errorf("plan[%d] size = %d, want %d", i, gotSize, wantSize)
+				}
+
+				wantStart += wantSize
+			}
+
+			if last := plan[len(plan)-1]; last.End != TotalSlotCount-1 {
+				t.Fatalf("plan's last slot = %d, want %d", last.End, TotalSlotCount-1)
+			}
+		})
+	}
+}
+
+func TestSlotPlanNoOverlapOrGaps(t *testing.T) {
+	for _, primaryCount := range []int{1, 2, 3, 7, 16, 100} {
+		plan := SlotPlan(primaryCount)
+
+		for i := 1; i < len(plan); i++ {
+			if plan[i].Start != plan[i-1].End+1 {
+				t.Fatalf("SlotPlan(%d): plan[%d].Start = %d, want %d (immediately after plan[%d].End)",
+					primaryCount, i, plan[i].Start, plan[i-1].End+1, i-1)
+			}
+		}
+	}
+}
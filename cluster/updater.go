@@ -2,19 +2,50 @@ package cluster
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"net"
+	"strconv"
 	"sync"
+	"time"
 
 	v1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/tkhq/valkey-manager/api/v1alpha1"
 )
 
 type updateHandler struct {
-	updater func(*v1.StatefulSet)
+	ourIndex uint32
 
-	clusterConfigured bool
+	// vcClient and vcName are set when this StatefulSet is owned by a ValkeyCluster; shard/role
+	// assignment is then read from its status instead of the index heuristic, and observed
+	// cluster health is published back onto it.
+	vcClient *v1alpha1.Client
+	vcName   string
+
+	queue workqueue.RateLimitingInterface
 
 	mu sync.RWMutex
+
+	// objects holds the most recently observed StatefulSet (and the replica count it is
+	// replacing) for each queued key, since the workqueue itself only carries keys.
+	objects map[string]queuedUpdate
+
+	clusterConfigured bool
+	lastReconcileErr  error
+	lastSuccess       time.Time
+
+	// lastPrimaryCount is the primary count last reconciled against, used to serve PlanDrift
+	// without needing to re-derive it from a StatefulSet.
+	lastPrimaryCount int
+
+	// lastShards is the ValkeyCluster status.shards last reconciled against, or nil if this
+	// StatefulSet isn't (yet) backed by one. When set, PlanDrift verifies against it instead of
+	// lastPrimaryCount, since primaries aren't contiguous pod indices once ReplicasPerShard > 0.
+	lastShards []v1alpha1.ShardStatus
 }
 
 func (h *updateHandler) ClusterConfigured() bool {
@@ -24,6 +55,30 @@ func (h *updateHandler) ClusterConfigured() bool {
 	return h.clusterConfigured
 }
 
+func (h *updateHandler) LastReconcileError() error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.lastReconcileErr
+}
+
+func (h *updateHandler) LastSuccess() time.Time {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.lastSuccess
+}
+
+// queuedUpdate is the work item stashed alongside a queued key, since the workqueue itself only
+// carries keys.
+type queuedUpdate struct {
+	ss *v1.StatefulSet
+
+	// previousReplicaCount is the replica count being replaced by ss, or -1 if none has been
+	// observed yet.
+	previousReplicaCount int32
+}
+
 func (h *updateHandler) OnAdd(obj any, isInInitialList bool) {
 	ss, ok := obj.(*v1.StatefulSet)
 	if !ok {
@@ -32,7 +87,7 @@ func (h *updateHandler) OnAdd(obj any, isInInitialList bool) {
 		return
 	}
 
-	h.updater(ss)
+	h.enqueue(ss, -1)
 }
 
 func (h *updateHandler) OnUpdate(oldObj, newObj any) {
@@ -57,37 +112,216 @@ func (h *updateHandler) OnUpdate(oldObj, newObj any) {
 		return
 	}
 
-	h.updater(newSS)
+	h.enqueue(newSS, *oldSS.Spec.Replicas)
 }
 
 func (h *updateHandler) OnDelete(_ any) {
 	// Nothing we can do
 }
 
-type ResourceHandler interface {
-	cache.ResourceEventHandler
+// enqueue records the latest observed StatefulSet and schedules a reconcile of its key. It is
+// intentionally cheap: the heavy lifting of actually talking to valkey happens on a worker
+// goroutine, so a burst of informer events never blocks on the handler mutex.
+func (h *updateHandler) enqueue(ss *v1.StatefulSet, previousReplicaCount int32) {
+	key, err := cache.MetaNamespaceKeyFunc(ss)
+	if err != nil {
+		slog.Error("failed to compute key for StatefulSet", slog.String("error", err.Error()))
 
-	// ClusterConfigured indicates the the cluster has been successfully configured.
-	ClusterConfigured() bool
+		return
+	}
+
+	h.mu.Lock()
+	h.objects[key] = queuedUpdate{ss: ss, previousReplicaCount: previousReplicaCount}
+	h.mu.Unlock()
+
+	h.queue.Add(key)
 }
 
-func UpdateHandler(ctx context.Context, ourIndex uint32) ResourceHandler {
-	h := new(updateHandler)
+// Run starts numWorkers reconcile loops and blocks until ctx is cancelled, at which point the
+// queue is shut down and all workers drain.
+func (h *updateHandler) Run(ctx context.Context, numWorkers int) {
+	defer h.queue.ShutDown()
 
-	h.updater = func(ss *v1.StatefulSet) {
-		h.mu.Lock()
-		defer h.mu.Unlock()
+	var wg sync.WaitGroup
+
+	for range numWorkers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			wait.UntilWithContext(ctx, h.runWorker, time.Second)
+		}()
+	}
+
+	<-ctx.Done()
+
+	wg.Wait()
+}
+
+func (h *updateHandler) runWorker(ctx context.Context) {
+	for h.processNextWorkItem(ctx) {
+	}
+}
+
+func (h *updateHandler) processNextWorkItem(ctx context.Context) bool {
+	key, shutdown := h.queue.Get()
+	if shutdown {
+		return false
+	}
+
+	defer h.queue.Done(key)
+
+	h.mu.RLock()
+	qu, ok := h.objects[key.(string)]
+	h.mu.RUnlock()
+
+	if !ok || qu.ss == nil {
+		slog.Warn("no known StatefulSet for queued key", slog.String("key", key.(string)))
+
+		h.queue.Forget(key)
+
+		return true
+	}
 
-		if err := Configure(ctx, ss, ourIndex); err != nil {
-			slog.Error("failed to reconfigure cluster after replica count change", slog.String("error", err.Error()))
+	var (
+		shards []v1alpha1.ShardStatus
+		vc     *v1alpha1.ValkeyCluster
+	)
 
-			h.clusterConfigured = false
+	if h.vcClient != nil {
+		var err error
 
-			return
+		vc, err = h.vcClient.Get(ctx, h.vcName)
+		if err != nil {
+			slog.Warn("failed to fetch ValkeyCluster status; falling back to index heuristic",
+				slog.String("name", h.vcName),
+				slog.String("error", err.Error()),
+			)
+		} else {
+			shards = vc.Status.Shards
 		}
+	}
+
+	if err := Configure(ctx, qu.ss, h.ourIndex, qu.previousReplicaCount, shards); err != nil {
+		slog.Error("failed to reconfigure cluster, will retry with backoff",
+			slog.String("key", key.(string)),
+			slog.Int("num_requeues", h.queue.NumRequeues(key)),
+			slog.String("error", err.Error()),
+		)
+
+		h.mu.Lock()
+		h.clusterConfigured = false
+		h.lastReconcileErr = err
+		h.mu.Unlock()
 
-		h.clusterConfigured = true
+		h.queue.AddRateLimited(key)
+
+		return true
 	}
 
-	return h
+	primaryCount, _ := primariesAndReplicas(int(*qu.ss.Spec.Replicas))
+
+	h.mu.Lock()
+	h.clusterConfigured = true
+	h.lastReconcileErr = nil
+	h.lastSuccess = time.Now()
+	h.lastPrimaryCount = primaryCount
+	h.lastShards = shards
+	h.mu.Unlock()
+
+	if h.vcClient != nil && vc != nil {
+		h.publishHealth(ctx, vc)
+	}
+
+	h.queue.Forget(key)
+
+	return true
+}
+
+// PlanDrift reports any drift between the shard's desired slot plan (as of the most recent
+// successful reconcile) and the slot ownership actually observed via CLUSTER SLOTS. It is exposed
+// over /planz so an operator can see whether a migration is required without waiting for the next
+// scale event to trigger one.
+func (h *updateHandler) PlanDrift(ctx context.Context) (PlanDrift, error) {
+	h.mu.RLock()
+	primaryCount := h.lastPrimaryCount
+	shards := h.lastShards
+	h.mu.RUnlock()
+
+	if primaryCount == 0 && len(shards) == 0 {
+		return PlanDrift{}, fmt.Errorf("no successful reconcile yet; primary count is unknown")
+	}
+
+	vc, err := WaitPing(ctx, net.JoinHostPort("127.0.0.1", strconv.Itoa(ValkeyPort)))
+	if err != nil {
+		return PlanDrift{}, fmt.Errorf("failed to connect to local redis: %w", err)
+	}
+
+	if len(shards) > 0 {
+		return VerifyPlanFromShards(ctx, vc, shards)
+	}
+
+	return VerifyPlan(ctx, vc, primaryCount)
+}
+
+func (h *updateHandler) publishHealth(ctx context.Context, vc *v1alpha1.ValkeyCluster) {
+	localVC, err := WaitPing(ctx, net.JoinHostPort("127.0.0.1", strconv.Itoa(ValkeyPort)))
+	if err != nil {
+		slog.Warn("failed to connect to local redis to publish cluster health", slog.String("error", err.Error()))
+
+		return
+	}
+
+	infoReader, err := localVC.Do(ctx, localVC.B().ClusterInfo().Build()).ToString()
+	if err != nil {
+		slog.Warn("failed to read cluster info to publish cluster health", slog.String("error", err.Error()))
+
+		return
+	}
+
+	info, err := InfoFromString(infoReader)
+	if err != nil {
+		slog.Warn("failed to parse cluster info to publish cluster health", slog.String("error", err.Error()))
+
+		return
+	}
+
+	if err := PublishHealth(ctx, h.vcClient, vc, info); err != nil {
+		slog.Warn("failed to publish cluster health", slog.String("error", err.Error()))
+	}
+}
+
+type ResourceHandler interface {
+	cache.ResourceEventHandler
+
+	// ClusterConfigured indicates the the cluster has been successfully configured.
+	ClusterConfigured() bool
+
+	// LastReconcileError returns the error from the most recent reconcile attempt, or nil if the
+	// most recent attempt succeeded.
+	LastReconcileError() error
+
+	// LastSuccess returns the time of the most recent successful reconcile.
+	LastSuccess() time.Time
+
+	// PlanDrift reports any drift between the desired SlotPlan and the slot ownership actually
+	// observed via CLUSTER SLOTS, as of the most recent successful reconcile.
+	PlanDrift(ctx context.Context) (PlanDrift, error)
+
+	// Run starts numWorkers reconcile loops and blocks until ctx is cancelled.
+	Run(ctx context.Context, numWorkers int)
+}
+
+// UpdateHandler builds a ResourceHandler for the StatefulSet at ourIndex. vcClient and vcName may
+// be the zero value/empty string, in which case role assignment falls back to the index heuristic
+// and cluster health is not published anywhere.
+func UpdateHandler(ourIndex uint32, vcClient *v1alpha1.Client, vcName string) ResourceHandler {
+	return &updateHandler{
+		ourIndex: ourIndex,
+		vcClient: vcClient,
+		vcName:   vcName,
+		queue:    workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		objects:  map[string]queuedUpdate{},
+	}
 }
@@ -0,0 +1,151 @@
+// Package controller reconciles ValkeyCluster objects: it keeps the underlying StatefulSet's
+// replica count in sync with spec.shards/spec.replicasPerShard, and lays out the shard/slot plan
+// each pod's sidecar reads its role from.
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/tkhq/valkey-manager/api/v1alpha1"
+	"github.com/tkhq/valkey-manager/cluster"
+)
+
+// ReconcileInterval is how often the controller re-reconciles every ValkeyCluster in its
+// namespace. A full informer/workqueue-based watch, matching the per-pod sidecar's reconciler, can
+// replace this polling loop once the generated clientset/informers land.
+const ReconcileInterval = 30 * time.Second
+
+// Config is the environment configuration for running this binary in controller mode (see
+// main.go's CONTROLLER_MODE), instead of as a per-pod sidecar. Deployed as a single-replica
+// Deployment alongside the ValkeyCluster-backed StatefulSets it reconciles.
+type Config struct {
+	// Namespace is the kubernetes namespace this controller reconciles ValkeyClusters in.
+	Namespace string `env:"NAMESPACE, required"`
+}
+
+// Controller reconciles ValkeyCluster objects.
+type Controller struct {
+	vcClient  *v1alpha1.Client
+	kc        kubernetes.Interface
+	namespace string
+}
+
+func NewController(kc kubernetes.Interface, vcClient *v1alpha1.Client, namespace string) *Controller {
+	return &Controller{vcClient: vcClient, kc: kc, namespace: namespace}
+}
+
+// Run blocks, reconciling every ValkeyCluster in the Controller's namespace on ReconcileInterval,
+// until ctx is cancelled.
+func (c *Controller) Run(ctx context.Context) {
+	ticker := time.NewTicker(ReconcileInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.reconcileAll(ctx); err != nil {
+			slog.Error("failed to reconcile ValkeyClusters", slog.String("error", err.Error()))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Controller) reconcileAll(ctx context.Context) error {
+	list, err := c.vcClient.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list ValkeyClusters: %w", err)
+	}
+
+	for i := range list.Items {
+		if err := c.reconcile(ctx, &list.Items[i]); err != nil {
+			slog.Error("failed to reconcile ValkeyCluster",
+				slog.String("name", list.Items[i].Name),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+
+	return nil
+}
+
+func (c *Controller) reconcile(ctx context.Context, vc *v1alpha1.ValkeyCluster) error {
+	desiredReplicas := vc.Spec.Shards * (1 + vc.Spec.ReplicasPerShard)
+
+	if err := c.reconcileStatefulSet(ctx, vc, desiredReplicas); err != nil {
+		return fmt.Errorf("failed to reconcile StatefulSet: %w", err)
+	}
+
+	vc.Status.Shards = shardPlan(vc)
+
+	if _, err := c.vcClient.UpdateStatus(ctx, vc); err != nil {
+		return fmt.Errorf("failed to publish status: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Controller) reconcileStatefulSet(ctx context.Context, vc *v1alpha1.ValkeyCluster, desiredReplicas int32) error {
+	ss, err := c.kc.AppsV1().StatefulSets(c.namespace).Get(ctx, vc.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		slog.Warn("no StatefulSet found for ValkeyCluster yet", slog.String("name", vc.Name))
+
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if ss.Spec.Replicas != nil && *ss.Spec.Replicas == desiredReplicas {
+		return nil
+	}
+
+	ss = ss.DeepCopy()
+	ss.Spec.Replicas = &desiredReplicas
+
+	_, err = c.kc.AppsV1().StatefulSets(c.namespace).Update(ctx, ss, metav1.UpdateOptions{})
+
+	return err
+}
+
+// shardPlan lays out shard index, slot range and pod names from spec, using the StatefulSet's
+// standard "<name>-<ordinal>" pod naming: primaries are pods [0, shards*(1+replicasPerShard)) at a
+// stride of (1+replicasPerShard), and each primary's replicas are the pods that follow it. Slot
+// ranges come from cluster.SlotPlan, so the controller and the sidecar's own index-heuristic path
+// never disagree about how slots are split across shards.
+func shardPlan(vc *v1alpha1.ValkeyCluster) []v1alpha1.ShardStatus {
+	slotPlan := cluster.SlotPlan(int(vc.Spec.Shards))
+
+	shards := make([]v1alpha1.ShardStatus, 0, vc.Spec.Shards)
+
+	for i := int32(0); i < vc.Spec.Shards; i++ {
+		primaryIndex := i * (1 + vc.Spec.ReplicasPerShard)
+
+		replicaNames := make([]string, 0, vc.Spec.ReplicasPerShard)
+		for r := int32(1); r <= vc.Spec.ReplicasPerShard; r++ {
+			replicaNames = append(replicaNames, podName(vc.Name, primaryIndex+r))
+		}
+
+		shards = append(shards, v1alpha1.ShardStatus{
+			Index:           i,
+			PrimaryPodName:  podName(vc.Name, primaryIndex),
+			ReplicaPodNames: replicaNames,
+			SlotStart:       int32(slotPlan[i].Start),
+			SlotEnd:         int32(slotPlan[i].End),
+		})
+	}
+
+	return shards
+}
+
+func podName(statefulSetName string, ordinal int32) string {
+	return fmt.Sprintf("%s-%d", statefulSetName, ordinal)
+}
@@ -5,15 +5,21 @@ import (
 	"fmt"
 	"log"
 	"log/slog"
+	"net"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 
 	"github.com/sethvargo/go-envconfig"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 
+	"github.com/tkhq/valkey-manager/api/v1alpha1"
+	"github.com/tkhq/valkey-manager/backup"
 	"github.com/tkhq/valkey-manager/cluster"
+	"github.com/tkhq/valkey-manager/controller"
 	"github.com/tkhq/valkey-manager/manager"
 )
 
@@ -21,6 +27,12 @@ func main() {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
+	if controllerModeEnabled() {
+		runController(ctx)
+
+		return
+	}
+
 	cfg, err := loadConfig(ctx)
 	if err != nil {
 		log.Fatal("failed to load configuration: ", err)
@@ -30,16 +42,78 @@ func main() {
 		slog.SetLogLoggerLevel(slog.LevelDebug)
 	}
 
-	kc, err := getKubernetesClient()
+	if err := loadTLSCreds(cfg); err != nil {
+		log.Fatal("failed to load TLS/AUTH material: ", err)
+	}
+
+	restCfg, err := rest.InClusterConfig()
+	if err != nil {
+		log.Fatal("failed to create in-cluster kubernetes config: ", err)
+	}
+
+	kc, err := kubernetes.NewForConfig(restCfg)
 	if err != nil {
 		log.Fatal("failed to create kubernetes client: ", err)
 	}
 
-	manager.NewManager(kc, cfg).Run(ctx, cluster.UpdateHandler(ctx, cfg.Index))
+	var vcClient *v1alpha1.Client
+
+	if cfg.ValkeyClusterName != "" {
+		vcClient, err = v1alpha1.NewForConfig(restCfg, cfg.Namespace)
+		if err != nil {
+			log.Fatal("failed to create ValkeyCluster client: ", err)
+		}
+	}
+
+	if cfg.BackupS3Endpoint != "" {
+		if err := runBackupSubsystem(ctx, cfg, vcClient); err != nil {
+			log.Fatal("failed to start backup subsystem: ", err)
+		}
+	}
+
+	manager.NewManager(kc, cfg).Run(ctx, cluster.UpdateHandler(cfg.Index, vcClient, cfg.ValkeyClusterName))
 
 	log.Fatal("valkey manager exited")
 }
 
+// controllerModeEnabled reports whether this process should run as the ValkeyCluster controller
+// (see runController) rather than as a per-pod sidecar.
+func controllerModeEnabled() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv("CONTROLLER_MODE"))
+
+	return enabled
+}
+
+// runController runs this binary as the ValkeyCluster controller: it reconciles every
+// ValkeyCluster's StatefulSet replica count and shard/slot status in its namespace until ctx is
+// cancelled. It is deployed separately from the per-pod sidecars it lays out work for.
+func runController(ctx context.Context) {
+	ctrlCfg := new(controller.Config)
+
+	if err := envconfig.Process(ctx, ctrlCfg); err != nil {
+		log.Fatal("failed to load controller configuration: ", err)
+	}
+
+	restCfg, err := rest.InClusterConfig()
+	if err != nil {
+		log.Fatal("failed to create in-cluster kubernetes config: ", err)
+	}
+
+	kc, err := kubernetes.NewForConfig(restCfg)
+	if err != nil {
+		log.Fatal("failed to create kubernetes client: ", err)
+	}
+
+	vcClient, err := v1alpha1.NewForConfig(restCfg, ctrlCfg.Namespace)
+	if err != nil {
+		log.Fatal("failed to create ValkeyCluster client: ", err)
+	}
+
+	controller.NewController(kc, vcClient, ctrlCfg.Namespace).Run(ctx)
+
+	log.Fatal("valkey-manager controller exited")
+}
+
 func loadConfig(ctx context.Context) (*manager.Config, error) {
 	cfg := new(manager.Config)
 
@@ -47,14 +121,130 @@ func loadConfig(ctx context.Context) (*manager.Config, error) {
 		return nil, err
 	}
 
+	if cfg.ClusterName == "" {
+		cfg.ClusterName = cfg.Namespace
+	}
+
 	return cfg, nil
 }
 
-func getKubernetesClient() (kubernetes.Interface, error) {
-	cfg, err := rest.InClusterConfig()
+// loadTLSCreds performs the one-time startup load of cluster.DefaultClientFactory from
+// cfg.TLSCertFile/TLSKeyFile/TLSCAFile/PasswordFile. If cfg.TLSSecretRef is also set, this load is
+// superseded once the TLS secret watcher's informer first syncs.
+func loadTLSCreds(cfg *manager.Config) error {
+	var (
+		certPEM, keyPEM, caPEM []byte
+		password               string
+		err                    error
+	)
+
+	if cfg.TLSCertFile != "" {
+		if certPEM, err = os.ReadFile(cfg.TLSCertFile); err != nil {
+			return fmt.Errorf("failed to read TLS cert file %q: %w", cfg.TLSCertFile, err)
+		}
+
+		if keyPEM, err = os.ReadFile(cfg.TLSKeyFile); err != nil {
+			return fmt.Errorf("failed to read TLS key file %q: %w", cfg.TLSKeyFile, err)
+		}
+	}
+
+	if cfg.TLSCAFile != "" {
+		if caPEM, err = os.ReadFile(cfg.TLSCAFile); err != nil {
+			return fmt.Errorf("failed to read TLS CA file %q: %w", cfg.TLSCAFile, err)
+		}
+	}
+
+	if cfg.PasswordFile != "" {
+		raw, err := os.ReadFile(cfg.PasswordFile)
+		if err != nil {
+			return fmt.Errorf("failed to read password file %q: %w", cfg.PasswordFile, err)
+		}
+
+		password = strings.TrimSpace(string(raw))
+	}
+
+	return cluster.DefaultClientFactory.Update(certPEM, keyPEM, caPEM, password)
+}
+
+// runBackupSubsystem performs a one-time restore (if configured), waiting for and signalling the
+// local valkey instance so the restored data is actually loaded regardless of container start
+// order, then starts the periodic backup scheduler in the background.
+func runBackupSubsystem(ctx context.Context, cfg *manager.Config, vcClient *v1alpha1.Client) error {
+	b, err := backup.NewBackupper(backup.Config{
+		S3Endpoint:  cfg.BackupS3Endpoint,
+		S3Bucket:    cfg.BackupS3Bucket,
+		Schedule:    cfg.BackupSchedule,
+		Retention:   cfg.BackupRetention,
+		ClusterName: cfg.ClusterName,
+		DataDir:     cfg.DataDir,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create backupper: %w", err)
+	}
+
+	shardIndex := resolveShardIndex(ctx, cfg, vcClient)
+
+	if cfg.RestoreFrom != "" {
+		vc, err := cluster.WaitPing(ctx, net.JoinHostPort("127.0.0.1", strconv.Itoa(cluster.ValkeyPort)))
+		if err != nil {
+			return fmt.Errorf("failed to connect to local redis to restore snapshot: %w", err)
+		}
+
+		if err := b.Restore(ctx, vc, cfg.RestoreFrom); err != nil {
+			return fmt.Errorf("failed to restore snapshot %q: %w", cfg.RestoreFrom, err)
+		}
+
+		slog.Info("restored snapshot", slog.String("uri", cfg.RestoreFrom))
+	}
+
+	if cfg.BackupSchedule == "" {
+		return nil
+	}
+
+	go func() {
+		vc, err := cluster.WaitPing(ctx, net.JoinHostPort("127.0.0.1", strconv.Itoa(cluster.ValkeyPort)))
+		if err != nil {
+			slog.Error("backup subsystem failed to connect to local redis", slog.String("error", err.Error()))
+
+			return
+		}
+
+		if err := b.Run(ctx, vc, shardIndex); err != nil {
+			slog.Error("backup scheduler exited", slog.String("error", err.Error()))
+		}
+	}()
+
+	return nil
+}
+
+// resolveShardIndex returns the shard index backup snapshots should be keyed under: the pod's own
+// shard per status.shards if cfg.ValkeyClusterName names a ValkeyCluster, since pod ordinal no
+// longer equals shard index once ReplicasPerShard > 0, or cfg.Index otherwise. Falls back to
+// cfg.Index on any lookup failure, since pod ordinal remains a valid (if coarser) partition key
+// when status isn't available yet.
+func resolveShardIndex(ctx context.Context, cfg *manager.Config, vcClient *v1alpha1.Client) int {
+	if vcClient == nil {
+		return int(cfg.Index)
+	}
+
+	vc, err := vcClient.Get(ctx, cfg.ValkeyClusterName)
+	if err != nil {
+		slog.Warn("failed to fetch ValkeyCluster status; falling back to pod index for backup shard prefix", slog.String("error", err.Error()))
+
+		return int(cfg.Index)
+	}
+
+	podName, err := os.Hostname()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create in-cluster kubernetes config: %w", err)
+		slog.Warn("failed to determine pod hostname; falling back to pod index for backup shard prefix", slog.String("error", err.Error()))
+
+		return int(cfg.Index)
+	}
+
+	shard, _, found := cluster.RoleFromStatus(vc.Status.Shards, podName)
+	if !found {
+		return int(cfg.Index)
 	}
 
-	return kubernetes.NewForConfig(cfg)
+	return int(shard.Index)
 }
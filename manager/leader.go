@@ -0,0 +1,152 @@
+package manager
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/tkhq/valkey-manager/cluster"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+// ClusterConfiguredAnnotation is set on the leader election Lease by the current leader after
+// each reconcile, so followers (which never reconcile themselves) can mirror cluster health onto
+// their own /readyz.
+const ClusterConfiguredAnnotation = "valkey-manager.tkhq.io/cluster-configured"
+
+// leaderState tracks the identity of this process and of the current leader, plus the cluster
+// health mirrored from the leader when this process is a follower.
+type leaderState struct {
+	identity string
+
+	leader atomic.Value // string
+
+	mirroredConfigured atomic.Bool
+}
+
+func newLeaderState() *leaderState {
+	l := &leaderState{identity: podIdentity()}
+	l.leader.Store("")
+
+	return l
+}
+
+func (l *leaderState) isLeader() bool {
+	leaderIdentity, _ := l.leader.Load().(string)
+
+	return leaderIdentity != "" && leaderIdentity == l.identity
+}
+
+func (l *leaderState) currentLeader() string {
+	leaderIdentity, _ := l.leader.Load().(string)
+
+	return leaderIdentity
+}
+
+func podIdentity() string {
+	if host, err := os.Hostname(); err == nil && host != "" {
+		return host
+	}
+
+	return "unknown"
+}
+
+// runLeaderElection blocks until ctx is cancelled, repeatedly contending for the Manager's Lease.
+// Only while holding the lease does it run handler's reconcile workers; followers still serve
+// /healthz and /readyz (mirroring the leader's status, see runStatusMirror) but never call
+// cluster.Configure.
+func (m *Manager) runLeaderElection(ctx context.Context, handler cluster.ResourceHandler) {
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta: metav1.ObjectMeta{
+			Name:      m.leaseName,
+			Namespace: m.namespace,
+		},
+		Client: m.kc.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{
+			Identity: m.leaderState.identity,
+		},
+	}
+
+	leaderelection.RunOrDie(ctx, leaderelection.LeaderElectionConfig{
+		Lock:          lock,
+		LeaseDuration: m.leaseDuration,
+		RenewDeadline: m.renewDeadline,
+		RetryPeriod:   m.retryPeriod,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				slog.Info("acquired cluster leader election lease", slog.String("identity", m.leaderState.identity))
+
+				handler.Run(ctx, m.numWorkers)
+			},
+			OnStoppedLeading: func() {
+				slog.Info("lost cluster leader election lease", slog.String("identity", m.leaderState.identity))
+			},
+			OnNewLeader: func(leaderIdentity string) {
+				m.leaderState.leader.Store(leaderIdentity)
+
+				if leaderIdentity != m.leaderState.identity {
+					slog.Info("observed new cluster leader", slog.String("identity", leaderIdentity))
+				}
+			},
+		},
+	})
+}
+
+// runStatusMirror keeps cluster health visible to followers: the leader periodically publishes
+// handler.ClusterConfigured() onto the Lease as an annotation, and followers periodically read it
+// back so their own /readyz reflects the leader's reconcile status rather than their own (since
+// followers never reconcile).
+func (m *Manager) runStatusMirror(ctx context.Context, handler cluster.ResourceHandler) {
+	ticker := time.NewTicker(StatusMirrorInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		lease, err := m.kc.CoordinationV1().Leases(m.namespace).Get(ctx, m.leaseName, metav1.GetOptions{})
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				slog.Warn("failed to read leader election lease", slog.String("error", err.Error()))
+			}
+
+			continue
+		}
+
+		if m.leaderState.isLeader() {
+			m.publishClusterConfigured(ctx, lease, handler.ClusterConfigured())
+
+			continue
+		}
+
+		m.leaderState.mirroredConfigured.Store(lease.Annotations[ClusterConfiguredAnnotation] == "true")
+	}
+}
+
+func (m *Manager) publishClusterConfigured(ctx context.Context, lease *coordinationv1.Lease, configured bool) {
+	desired := strconv.FormatBool(configured)
+
+	if lease.Annotations[ClusterConfiguredAnnotation] == desired {
+		return
+	}
+
+	if lease.Annotations == nil {
+		lease.Annotations = map[string]string{}
+	}
+
+	lease.Annotations[ClusterConfiguredAnnotation] = desired
+
+	if _, err := m.kc.CoordinationV1().Leases(m.namespace).Update(ctx, lease, metav1.UpdateOptions{}); err != nil {
+		slog.Warn("failed to publish cluster-configured status to lease", slog.String("error", err.Error()))
+	}
+}
@@ -2,6 +2,7 @@ package manager
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"time"
 
@@ -14,6 +15,24 @@ import (
 
 var DefaultResync = time.Minute
 
+// DefaultNumWorkers is the number of reconcile workers started when Config.NumWorkers is unset.
+const DefaultNumWorkers = 1
+
+// StaleReconcileThreshold is how long it may be since the last successful reconcile before
+// /healthz starts reporting unhealthy, once at least one reconcile has been attempted.
+const StaleReconcileThreshold = 5 * time.Minute
+
+// Defaults for the leader election lease, mirroring client-go's own recommended defaults.
+const (
+	DefaultLeaseDuration = 15 * time.Second
+	DefaultRenewDeadline = 10 * time.Second
+	DefaultRetryPeriod   = 2 * time.Second
+)
+
+// StatusMirrorInterval is how often followers poll the leader election lease to mirror the
+// leader's ClusterConfigured status onto their own /readyz, and how often the leader publishes it.
+const StatusMirrorInterval = 5 * time.Second
+
 type Config struct {
 	// Debug enables debug logging.
 	Debug bool `env:"DEBUG"`
@@ -32,15 +51,104 @@ type Config struct {
 	// If not specified, a reasonable default will be used.
 	DefaultResync time.Duration `env:"DEFAULT_RESYNC"`
 
+	// NumWorkers is the number of reconcile workers draining the StatefulSet workqueue.
+	// If not specified, a reasonable default will be used.
+	NumWorkers int `env:"NUM_WORKERS"`
+
+	// LeaseName is the name of the Lease used to elect a single sidecar to perform cluster
+	// mutations. It must be the same across every sidecar for a given StatefulSet.
+	LeaseName string `env:"LEASE_NAME, default=valkey-manager"`
+
+	// LeaseDuration is how long a leader's lease is valid for without being renewed.
+	// If not specified, a reasonable default will be used.
+	LeaseDuration time.Duration `env:"LEASE_DURATION"`
+
+	// RenewDeadline is how long the leader will retry refreshing its lease before giving it up.
+	// If not specified, a reasonable default will be used.
+	RenewDeadline time.Duration `env:"RENEW_DEADLINE"`
+
+	// RetryPeriod is how long followers wait between attempts to acquire the lease.
+	// If not specified, a reasonable default will be used.
+	RetryPeriod time.Duration `env:"RETRY_PERIOD"`
+
 	// ListenAddr is the host:port on which the HTTP service (for health) should listen.
 	ListenAddr string `env:"LISTEN_ADDR, default=:8087"`
+
+	// DataDir is the directory valkey writes its RDB dump into.
+	DataDir string `env:"DATA_DIR, default=/data"`
+
+	// ClusterName namespaces backup snapshots from other clusters sharing the same bucket. Only
+	// meaningful when BackupS3Endpoint is set; defaults to Namespace if unset, since that's unique
+	// enough to namespace snapshots for deployments that don't otherwise need to set it.
+	ClusterName string `env:"CLUSTER_NAME"`
+
+	// BackupS3Endpoint is the S3-compatible endpoint snapshots are uploaded to and restored from.
+	// Backups are disabled if unset.
+	BackupS3Endpoint string `env:"BACKUP_S3_ENDPOINT"`
+
+	// BackupS3Bucket is the bucket snapshots are stored under.
+	BackupS3Bucket string `env:"BACKUP_S3_BUCKET"`
+
+	// BackupSchedule is a standard cron expression controlling how often this shard's primary
+	// takes a snapshot. Backups are disabled if unset.
+	BackupSchedule string `env:"BACKUP_SCHEDULE"`
+
+	// BackupRetention is the number of snapshots kept per shard.
+	BackupRetention int `env:"BACKUP_RETENTION, default=7"`
+
+	// RestoreFrom is an s3:// URI of a snapshot to restore into DataDir before this node joins
+	// the cluster. Restore is skipped if unset.
+	RestoreFrom string `env:"RESTORE_FROM"`
+
+	// ValkeyClusterName is the name of the owning ValkeyCluster, if this deployment has migrated
+	// to one. If set, shard/role assignment is read from its status subresource instead of the
+	// index-modulo-primaryCount heuristic, and observed cluster health is published back onto it.
+	ValkeyClusterName string `env:"VALKEY_CLUSTER_NAME"`
+
+	// TLSCertFile, TLSKeyFile, and TLSCAFile are PEM material securing every connection to the
+	// local valkey instance and its peers (CLUSTER MEET and slot migration included). Read once at
+	// startup; use TLSSecretRef instead if the material needs to be watched for rotation. TLS is
+	// disabled if TLSCertFile is unset.
+	TLSCertFile string `env:"VALKEY_TLS_CERT"`
+	TLSKeyFile  string `env:"VALKEY_TLS_KEY"`
+	TLSCAFile   string `env:"VALKEY_TLS_CA"`
+
+	// TLSSecretRef, if set, names a Secret in Namespace holding tls.crt/tls.key/ca.crt/password
+	// keys. It is watched via an informer so certificate rotation rebuilds the shared
+	// cluster.ClientFactory without a pod restart, taking precedence over TLSCertFile/TLSKeyFile/
+	// TLSCAFile above once it first syncs.
+	TLSSecretRef string `env:"TLS_SECRET_REF"`
+
+	// PasswordFile points to a file containing the AUTH password used for every connection to
+	// valkey. AUTH is disabled if unset and TLSSecretRef carries no password key.
+	PasswordFile string `env:"VALKEY_PASSWORD_FILE"`
+
+	// CertExpiryWarning is how far in advance of the TLS certificate's expiry /readyz starts
+	// reporting unhealthy, giving an operator a window to rotate it before connections start
+	// failing outright.
+	CertExpiryWarning time.Duration `env:"CERT_EXPIRY_WARNING, default=168h"`
 }
 
 // Manager implements a valkey manager, which configures the runtime cluster dynamics of a Valkey instance.
 // It is expected that the Valkey instance be a member of a StatefulSet.
 type Manager struct {
-	informer   v1.StatefulSetInformer
-	listenAddr string
+	kc kubernetes.Interface
+
+	informer      v1.StatefulSetInformer
+	listenAddr    string
+	numWorkers    int
+	defaultResync time.Duration
+
+	namespace     string
+	leaseName     string
+	leaseDuration time.Duration
+	renewDeadline time.Duration
+	retryPeriod   time.Duration
+
+	tlsSecretRef      string
+	certExpiryWarning time.Duration
+
+	leaderState *leaderState
 }
 
 func NewManager(kc kubernetes.Interface, cfg *Config) *Manager {
@@ -58,13 +166,40 @@ func NewManager(kc kubernetes.Interface, cfg *Config) *Manager {
 		cfg.DefaultResync = DefaultResync
 	}
 
+	if cfg.NumWorkers == 0 {
+		cfg.NumWorkers = DefaultNumWorkers
+	}
+
+	if cfg.LeaseDuration == 0 {
+		cfg.LeaseDuration = DefaultLeaseDuration
+	}
+
+	if cfg.RenewDeadline == 0 {
+		cfg.RenewDeadline = DefaultRenewDeadline
+	}
+
+	if cfg.RetryPeriod == 0 {
+		cfg.RetryPeriod = DefaultRetryPeriod
+	}
+
 	informerFactory := informers.NewSharedInformerFactoryWithOptions(kc, cfg.DefaultResync, opts...)
 
 	informer := informerFactory.Apps().V1().StatefulSets()
 
 	return &Manager{
-		informer:   informer,
-		listenAddr: cfg.ListenAddr,
+		kc:                kc,
+		informer:          informer,
+		listenAddr:        cfg.ListenAddr,
+		numWorkers:        cfg.NumWorkers,
+		defaultResync:     cfg.DefaultResync,
+		namespace:         cfg.Namespace,
+		leaseName:         cfg.LeaseName,
+		leaseDuration:     cfg.LeaseDuration,
+		renewDeadline:     cfg.RenewDeadline,
+		retryPeriod:       cfg.RetryPeriod,
+		tlsSecretRef:      cfg.TLSSecretRef,
+		certExpiryWarning: cfg.CertExpiryWarning,
+		leaderState:       newLeaderState(),
 	}
 }
 
@@ -73,7 +208,13 @@ func (m *Manager) Run(ctx context.Context, handler cluster.ResourceHandler) {
 
 	m.informer.Informer().AddEventHandler(handler)
 
-	m.informer.Informer().RunWithContext(ctx)
+	go m.informer.Informer().RunWithContext(ctx)
+
+	go m.runTLSWatcher(ctx)
+
+	go m.runStatusMirror(ctx, handler)
+
+	m.runLeaderElection(ctx, handler)
 }
 
 func (m *Manager) runHealthService(h cluster.ResourceHandler) {
@@ -82,18 +223,68 @@ func (m *Manager) runHealthService(h cluster.ResourceHandler) {
 	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
 		if h == nil || m == nil {
 			w.WriteHeader(http.StatusInternalServerError)
-		} else {
-			w.WriteHeader(http.StatusOK)
+
+			return
+		}
+
+		if lastErr := h.LastReconcileError(); lastErr != nil && time.Since(h.LastSuccess()) > StaleReconcileThreshold {
+			w.WriteHeader(http.StatusInternalServerError)
+
+			return
 		}
+
+		w.WriteHeader(http.StatusOK)
 	})
 
 	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
-		if h.ClusterConfigured() {
+		if expiry, ok := cluster.DefaultClientFactory.CertExpiry(); ok && time.Until(expiry) < m.certExpiryWarning {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(w, "TLS certificate expires at %s, within the %s warning window\n", expiry, m.certExpiryWarning)
+
+			return
+		}
+
+		if m.clusterConfigured(h) {
 			w.WriteHeader(http.StatusOK)
 		} else {
 			w.WriteHeader(http.StatusInternalServerError)
 		}
 	})
 
+	mux.HandleFunc("/leader", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "leader=%s\nis_leader=%t\n", m.leaderState.currentLeader(), m.leaderState.isLeader())
+	})
+
+	mux.HandleFunc("/planz", func(w http.ResponseWriter, r *http.Request) {
+		drift, err := h.PlanDrift(r.Context())
+		if err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintf(w, "failed to compute slot plan drift: %s\n", err)
+
+			return
+		}
+
+		if drift.Empty() {
+			fmt.Fprintln(w, "no drift; cluster matches desired slot plan")
+
+			return
+		}
+
+		w.WriteHeader(http.StatusConflict)
+		fmt.Fprintf(w, "missing_slots=%d overlapping_slots=%d misassigned_slots=%d\n",
+			len(drift.MissingSlots), len(drift.OverlappingSlots), len(drift.MisassignedSlots))
+	})
+
 	http.ListenAndServe(m.listenAddr, mux)
 }
+
+// clusterConfigured reports cluster health for /readyz: the leader reports its own reconciler
+// state directly, while followers (which never reconcile) report the status mirrored from the
+// leader via the leader election lease.
+func (m *Manager) clusterConfigured(h cluster.ResourceHandler) bool {
+	if m.leaderState.isLeader() {
+		return h.ClusterConfigured()
+	}
+
+	return m.leaderState.mirroredConfigured.Load()
+}
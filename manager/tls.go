@@ -0,0 +1,65 @@
+package manager
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/tkhq/valkey-manager/cluster"
+)
+
+// runTLSWatcher watches the Secret named by TLSSecretRef and rebuilds
+// cluster.DefaultClientFactory whenever its contents change, so certificate rotation takes effect
+// for new connections without a pod restart. It is a no-op if no TLSSecretRef was configured.
+func (m *Manager) runTLSWatcher(ctx context.Context) {
+	if m.tlsSecretRef == "" {
+		return
+	}
+
+	informerFactory := informers.NewSharedInformerFactoryWithOptions(m.kc, m.defaultResync,
+		informers.WithNamespace(m.namespace),
+		informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.FieldSelector = "metadata.name=" + m.tlsSecretRef
+		}),
+	)
+
+	informer := informerFactory.Core().V1().Secrets().Informer()
+
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj any) { m.applyTLSSecret(obj) },
+		UpdateFunc: func(_, obj any) { m.applyTLSSecret(obj) },
+	})
+
+	informer.RunWithContext(ctx)
+}
+
+func (m *Manager) applyTLSSecret(obj any) {
+	secret, ok := obj.(*corev1.Secret)
+	if !ok {
+		slog.Error("received a non-Secret object from the TLS secret informer")
+
+		return
+	}
+
+	err := cluster.DefaultClientFactory.Update(
+		secret.Data["tls.crt"],
+		secret.Data["tls.key"],
+		secret.Data["ca.crt"],
+		strings.TrimSpace(string(secret.Data["password"])),
+	)
+	if err != nil {
+		slog.Error("failed to apply updated TLS/AUTH material from secret",
+			slog.String("secret", secret.Name),
+			slog.String("error", err.Error()),
+		)
+
+		return
+	}
+
+	slog.Info("applied updated TLS/AUTH material", slog.String("secret", secret.Name))
+}